@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jpki/myna/libmyna"
+)
+
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "カードの処理時間を計測します",
+}
+
+var benchSignCmd = &cobra.Command{
+	Use:   "sign",
+	Short: "署名用秘密鍵での署名の所要時間を計測します",
+	Long: `1回の接続で署名を繰り返し、1回あたりの所要時間を表示します。
+接続と署名用パスワードの照合の時間は含みません。
+`,
+	RunE: benchSign,
+}
+
+func benchSign(cmd *cobra.Command, args []string) error {
+	count, _ := cmd.Flags().GetInt("count")
+	if count <= 0 {
+		cmd.Usage()
+		return errors.New("--countには1以上を指定してください")
+	}
+	pin, err := cmd.Flags().GetString("pin")
+	if pin == "" {
+		pin, err = inputPin("署名用パスワード(6-16桁): ")
+		if err != nil {
+			return nil
+		}
+	}
+	pin = strings.ToUpper(pin)
+
+	stats, err := libmyna.BenchmarkSign(pin, []byte("myna benchmark"), count)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("count: %d\n", stats.Count)
+	fmt.Printf("min:   %s\n", stats.Min)
+	fmt.Printf("avg:   %s\n", stats.Avg)
+	fmt.Printf("max:   %s\n", stats.Max)
+	fmt.Printf("p95:   %s\n", stats.P95)
+	return nil
+}
+
+func init() {
+	benchCmd.AddCommand(benchSignCmd)
+	benchSignCmd.Flags().StringP("pin", "p", "", "署名用パスワード(6-16桁)")
+	benchSignCmd.Flags().IntP("count", "n", 10, "署名の回数")
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jpki/myna/libmyna"
+)
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "実行環境の情報を表示",
+	Long: `mynaとGoのバージョン、OS、PC/SCライブラリ、リーダーの一覧を表示します。
+カードは不要です。不具合報告の際に出力を添付してください。
+`,
+	RunE: env,
+}
+
+func env(cmd *cobra.Command, args []string) error {
+	info, err := libmyna.GetSystemInfo()
+	if err != nil {
+		return err
+	}
+	form, _ := cmd.Flags().GetString("form")
+	switch form {
+	case "json":
+		out, _ := json.MarshalIndent(info, "", "  ")
+		fmt.Printf("%s\n", out)
+	default:
+		fmt.Printf("myna:     %s\n", info.Version)
+		fmt.Printf("Go:       %s\n", info.GoVersion)
+		fmt.Printf("OS/Arch:  %s/%s\n", info.OS, info.Arch)
+		fmt.Printf("PC/SC:    %s\n", info.PCSCVersion)
+		if info.PCSCError != "" {
+			fmt.Printf("エラー:   %s\n", info.PCSCError)
+		}
+		for i, name := range info.Readers {
+			fmt.Printf("Reader %d: %s\n", i, name)
+		}
+		var paths []string
+		for path := range info.Modules {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			fmt.Printf("Module:   %s %s\n", path, info.Modules[path])
+		}
+	}
+	return nil
+}
+
+func init() {
+	envCmd.Flags().StringP("form", "f", "text", "出力形式(text,json)")
+}
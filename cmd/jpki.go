@@ -43,11 +43,17 @@ func jpkiCert(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 	var cert *x509.Certificate
+	var chain []*x509.Certificate
 	var err error
 	var pin string
+	withChain, _ := cmd.Flags().GetBool("chain")
 	switch strings.ToUpper(args[0]) {
 	case "AUTH":
-		cert, err = libmyna.GetJPKIAuthCert()
+		if withChain {
+			chain, err = libmyna.GetJPKIAuthCertChain()
+		} else {
+			cert, err = libmyna.GetJPKIAuthCert()
+		}
 	case "AUTHCA":
 		cert, err = libmyna.GetJPKIAuthCACert()
 	case "SIGN":
@@ -60,7 +66,11 @@ func jpkiCert(cmd *cobra.Command, args []string) error {
 		}
 		pin = strings.ToUpper(pin)
 
-		cert, err = libmyna.GetJPKISignCert(pin)
+		if withChain {
+			chain, err = libmyna.GetJPKISignCertChain(pin)
+		} else {
+			cert, err = libmyna.GetJPKISignCert(pin)
+		}
 	case "SIGNCA":
 		cert, err = libmyna.GetJPKISignCACert()
 	default:
@@ -71,6 +81,14 @@ func jpkiCert(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if chain != nil {
+		form, _ := cmd.Flags().GetString("form")
+		if form == "p7b" {
+			return libmyna.ExportCertChainP7B(chain, "", "der")
+		}
+		return libmyna.WriteCertChainPEM(os.Stdout, chain)
+	}
+
 	err = outputCert(cert, cmd)
 	if err != nil {
 		return err
@@ -90,6 +108,12 @@ func outputCert(cert *x509.Certificate, cmd *cobra.Command) error {
 		os.Stdout.Write(cert.Raw)
 	case "ssh":
 		printCertSsh(cert)
+	case "json":
+		out, err := libmyna.CertSummaryJSON(cert)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s\n", out)
 	default:
 		cmd.Usage()
 		return nil
@@ -113,7 +137,9 @@ func printCertSsh(cert *x509.Certificate) {
 func init() {
 	jpkiCmd.AddCommand(jpkiCertCmd)
 	jpkiCertCmd.Flags().StringP(
-		"form", "f", "text", "出力形式(text|pem|der|ssh)")
+		"form", "f", "text", "出力形式(text|pem|der|ssh|json, --chainの場合はp7b)")
 	jpkiCertCmd.Flags().StringP(
 		"pin", "p", "", "パスワード(署名用証明書のみ)")
+	jpkiCertCmd.Flags().Bool(
+		"chain", false, "CA証明書を連結したPEM形式で出力(auth|signのみ)")
 }
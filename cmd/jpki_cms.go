@@ -3,7 +3,10 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"math/big"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -22,12 +25,48 @@ var jpkiCmsSignCmd = &cobra.Command{
 	RunE:  jpkiCmsSign,
 }
 
+var jpkiCmsSignBatchCmd = &cobra.Command{
+	Use:   "sign-batch [files...]",
+	Short: "複数のファイルにCMS署名を行います",
+	Long: `複数のファイルにCMS署名を行います
+出力ファイル名は--patternで指定します ({base}: foo.pdf, {name}: foo, {ext}: .pdf)
+出力ファイルが既に存在する場合は上書きせずにエラーとします
+`,
+	RunE: jpkiCmsSignBatch,
+}
+
 var jpkiCmsVerifyCmd = &cobra.Command{
 	Use:   "verify",
 	Short: "CMS署名を検証します",
 	RunE:  jpkiCmsVerify,
 }
 
+var jpkiCmsInspectCmd = &cobra.Command{
+	Use:   "inspect file",
+	Short: "CMS署名の構造を表示します",
+	Long: `署名者情報・ダイジェストアルゴリズム・署名属性・同梱された証明書を表示します
+署名の検証は行いません。DER形式とPEM形式のどちらでも読み込めます
+`,
+	RunE: jpkiCmsInspect,
+}
+
+func jpkiCmsInspect(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		cmd.Usage()
+		return errors.New("署名ファイルを指定してください")
+	}
+	data, err := ioutil.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	desc, err := libmyna.DescribeCMS(data)
+	if err != nil {
+		return err
+	}
+	fmt.Print(desc)
+	return nil
+}
+
 func jpkiCmsSign(cmd *cobra.Command, args []string) error {
 	in, _ := cmd.Flags().GetString("in")
 	if in == "" {
@@ -35,9 +74,77 @@ func jpkiCmsSign(cmd *cobra.Command, args []string) error {
 		return errors.New("署名対象ファイルを指定してください")
 	}
 	out, _ := cmd.Flags().GetString("out")
-	if out == "" {
+
+	pin, err := cmd.Flags().GetString("pin")
+	if pin == "" {
+		pin, err = inputPin("署名用パスワード(6-16桁): ")
+		if err != nil {
+			return nil
+		}
+	}
+	pin = strings.ToUpper(pin)
+
+	md, _ := cmd.Flags().GetString("md")
+	form, _ := cmd.Flags().GetString("form")
+	detached, _ := cmd.Flags().GetBool("detached")
+	chain, _ := cmd.Flags().GetBool("chain")
+	selfVerify, _ := cmd.Flags().GetBool("self-verify")
+	skipKeyUsage, _ := cmd.Flags().GetBool("skip-key-usage-check")
+	mode, _ := cmd.Flags().GetString("mode")
+	fileMode, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil {
 		cmd.Usage()
-		return errors.New("出力ファイルを指定してください")
+		return fmt.Errorf("パーミッションは8進数で指定してください: %s", mode)
+	}
+	opts := libmyna.CmsSignOpts{
+		Hash:              md,
+		Form:              form,
+		Detached:          detached,
+		IncludeChain:      chain,
+		SelfVerify:        selfVerify,
+		SkipKeyUsageCheck: skipKeyUsage,
+		FileMode:          os.FileMode(fileMode),
+	}
+	signerID, _ := cmd.Flags().GetString("signer-id")
+	switch signerID {
+	case "issuer-serial":
+	case "ski":
+		opts.SignerIDType = libmyna.SignerIDSubjectKeyID
+	default:
+		cmd.Usage()
+		return fmt.Errorf("不明なsigner-idです: %s", signerID)
+	}
+	commitment, _ := cmd.Flags().GetString("commitment")
+	switch commitment {
+	case "":
+	case "origin":
+		opts.SignedAttributes = append(opts.SignedAttributes,
+			libmyna.CommitmentTypeAttribute(libmyna.OIDCommitmentProofOfOrigin))
+	case "approval":
+		opts.SignedAttributes = append(opts.SignedAttributes,
+			libmyna.CommitmentTypeAttribute(libmyna.OIDCommitmentProofOfApproval))
+	case "creation":
+		opts.SignedAttributes = append(opts.SignedAttributes,
+			libmyna.CommitmentTypeAttribute(libmyna.OIDCommitmentProofOfCreation))
+	default:
+		cmd.Usage()
+		return fmt.Errorf("不明なcommitment-typeです: %s", commitment)
+	}
+	if bundle, _ := cmd.Flags().GetBool("bundle"); bundle {
+		out = libmyna.ResolveDefaultOutput(in, out, ".zip")
+		fmt.Fprintf(os.Stderr, "出力ファイル: %s\n", out)
+		return libmyna.CmsSignBundle(pin, in, out, opts)
+	}
+	out = libmyna.ResolveDefaultOutput(in, out, libmyna.CmsOutputExt(opts))
+	fmt.Fprintf(os.Stderr, "出力ファイル: %s\n", out)
+	err = libmyna.CmsSignJPKISign(pin, in, out, opts)
+	return err
+}
+
+func jpkiCmsSignBatch(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		cmd.Usage()
+		return errors.New("署名対象ファイルを指定してください")
 	}
 
 	pin, err := cmd.Flags().GetString("pin")
@@ -52,8 +159,21 @@ func jpkiCmsSign(cmd *cobra.Command, args []string) error {
 	md, _ := cmd.Flags().GetString("md")
 	form, _ := cmd.Flags().GetString("form")
 	detached, _ := cmd.Flags().GetBool("detached")
-	opts := libmyna.CmsSignOpts{md, form, detached}
-	err = libmyna.CmsSignJPKISign(pin, in, out, opts)
+	dir, _ := cmd.Flags().GetString("dir")
+	pattern, _ := cmd.Flags().GetString("pattern")
+	opts := libmyna.CmsSignBatchOpts{
+		CmsSignOpts: libmyna.CmsSignOpts{
+			Hash:     md,
+			Form:     form,
+			Detached: detached,
+		},
+		OutputDir:     dir,
+		OutputPattern: pattern,
+	}
+	outputs, err := libmyna.CmsSignJPKISignBatch(pin, args, opts)
+	for _, out := range outputs {
+		fmt.Println(out)
+	}
 	return err
 }
 
@@ -81,11 +201,47 @@ func jpkiCmsVerify(cmd *cobra.Command, args []string) error {
 			"警告: -c は --detached時のみ有効です。'%s'の内容は無視されます。\n", content)
 	}
 
-	opts := libmyna.CmsVerifyOpts{form, detached, content}
-	err := libmyna.CmsVerifyJPKISign(args[0], opts)
+	opts := libmyna.CmsVerifyOpts{
+		Form:     form,
+		Detached: detached,
+		Content:  content,
+	}
+	if tsaCA, _ := cmd.Flags().GetStringSlice("tsa-ca"); len(tsaCA) > 0 {
+		roots, err := libmyna.LoadCertPool(tsaCA...)
+		if err != nil {
+			return err
+		}
+		opts.TSARoots = roots
+	}
+	opts.ExpectedSubject, _ = cmd.Flags().GetString("expected-subject")
+	serial, _ := cmd.Flags().GetString("expected-serial")
+	if serial != "" {
+		var ok bool
+		opts.ExpectedSerial, ok = new(big.Int).SetString(serial, 16)
+		if !ok {
+			cmd.Usage()
+			return fmt.Errorf("シリアル番号は16進数で指定してください: %s", serial)
+		}
+	}
+	if bundle, _ := cmd.Flags().GetBool("bundle"); bundle {
+		manifest, err := libmyna.VerifyBundle(args[0], opts)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("File:      %s\n", manifest.File)
+		fmt.Printf("Hash:      %s\n", manifest.Hash)
+		fmt.Printf("Signer:    %s\n", manifest.SignerSerial)
+		fmt.Printf("Signed at: %s\n", manifest.SignedAt)
+		fmt.Printf("Verification successful\n")
+		return nil
+	}
+	genTime, err := libmyna.CmsVerifyJPKISignWithTimestamp(args[0], opts)
 	if err != nil {
 		return err
 	}
+	if genTime != nil {
+		fmt.Printf("Timestamp: %s\n", genTime)
+	}
 	fmt.Printf("Verification successful\n")
 	return nil
 }
@@ -98,14 +254,39 @@ func init() {
 	jpkiCmsSignCmd.Flags().StringP(
 		"in", "i", "", "署名対象ファイル")
 	jpkiCmsSignCmd.Flags().StringP(
-		"out", "o", "", "出力ファイル")
+		"out", "o", "", "出力ファイル (省略時やディレクトリの場合は署名の形式から名前を決めます)")
 	jpkiCmsSignCmd.Flags().StringP(
-		"md", "m", "sha1", "ダイジェストアルゴリズム(sha1|sha256|sha512)")
+		"md", "m", "", "ダイジェストアルゴリズム("+
+			strings.Join(libmyna.SupportedDigests(), "|")+") 省略時は証明書の鍵長から選択")
 	jpkiCmsSignCmd.Flags().StringP("form", "f", "der", "出力形式(pem,der)")
 	jpkiCmsSignCmd.Flags().Bool("detached", false, "デタッチ署名 (Detached Signature)")
+	jpkiCmsSignCmd.Flags().Bool("chain", false, "署名用CA証明書を含める")
+	jpkiCmsSignCmd.Flags().Bool("self-verify", false, "出力する前に署名を検証する")
+	jpkiCmsSignCmd.Flags().Bool("skip-key-usage-check", false, "証明書の鍵用途(nonRepudiation)を確認しない")
+	jpkiCmsSignCmd.Flags().String("mode", "0644", "出力ファイルのパーミッション")
+	jpkiCmsSignCmd.Flags().String("signer-id", "issuer-serial", "署名者の参照方法 (issuer-serial|ski)")
+	jpkiCmsSignCmd.Flags().Bool("bundle", false, "元のファイルとデタッチ署名をzipにまとめて出力する")
+	jpkiCmsSignCmd.Flags().String("commitment", "", "commitment-type-indication属性(origin,approval,creation)")
+
+	jpkiCmsCmd.AddCommand(jpkiCmsSignBatchCmd)
+	jpkiCmsSignBatchCmd.Flags().StringP(
+		"pin", "p", "", "署名用パスワード(6-16桁)")
+	jpkiCmsSignBatchCmd.Flags().StringP(
+		"md", "m", "", "ダイジェストアルゴリズム("+
+			strings.Join(libmyna.SupportedDigests(), "|")+") 省略時は証明書の鍵長から選択")
+	jpkiCmsSignBatchCmd.Flags().StringP("form", "f", "der", "出力形式(pem,der)")
+	jpkiCmsSignBatchCmd.Flags().Bool("detached", false, "デタッチ署名 (Detached Signature)")
+	jpkiCmsSignBatchCmd.Flags().StringP("dir", "o", "", "出力ディレクトリ")
+	jpkiCmsSignBatchCmd.Flags().String("pattern", libmyna.DefaultOutputPattern, "出力ファイル名のパターン")
+
+	jpkiCmsCmd.AddCommand(jpkiCmsInspectCmd)
 
 	jpkiCmsCmd.AddCommand(jpkiCmsVerifyCmd)
 	jpkiCmsVerifyCmd.Flags().StringP("content", "c", "", "デタッチ署名の検証対象ファイル (--detached時のみ有効)")
 	jpkiCmsVerifyCmd.Flags().Bool("detached", false, "デタッチ署名 (Detached Signature)")
 	jpkiCmsVerifyCmd.Flags().StringP("form", "f", "der", "入力形式(pem,der)")
+	jpkiCmsVerifyCmd.Flags().String("expected-serial", "", "署名者の証明書のシリアル番号(16進数)")
+	jpkiCmsVerifyCmd.Flags().String("expected-subject", "", "署名者の証明書のSubject(RFC 2253形式)")
+	jpkiCmsVerifyCmd.Flags().Bool("bundle", false, "CmsSignBundleで作成したzipを検証する")
+	jpkiCmsVerifyCmd.Flags().StringSlice("tsa-ca", nil, "タイムスタンプを検証するTSAの信頼点の証明書ファイル(PEM,DER)")
 }
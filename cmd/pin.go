@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -165,6 +166,72 @@ func pinChangeJPKISign(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var pinChangeAllCmd = &cobra.Command{
+	Use:   "all",
+	Short: "券面入力補助用PIN・JPKI認証用PIN・JPKI署名用パスワードをまとめて変更",
+	Long: `券面入力補助用PIN・JPKI認証用PIN・JPKI署名用パスワードをまとめて変更します
+券面入力補助用PINとJPKI認証用PINは同じ4桁の数字に変更します
+JPKI署名用パスワードは6-16文字の英大文字と数字です
+新しいJPKI署名用パスワードを空にした場合は変更しません
+`,
+	RunE: pinChangeAll,
+}
+
+func pinChangeAll(cmd *cobra.Command, args []string) error {
+	fmt.Println(cmd.Long)
+	names := map[string]string{
+		"CARD_INPUT_HELPER": "券面入力補助用PIN(4桁)",
+		"JPKI_AUTH":         "JPKI認証用PIN(4桁)",
+		"JPKI_SIGN":         "JPKI署名用パスワード(6-16文字)",
+	}
+	pintypes := []string{"CARD_INPUT_HELPER", "JPKI_AUTH", "JPKI_SIGN"}
+	oldPins := map[string]string{}
+	for _, pintype := range pintypes {
+		pin, err := inputPin(fmt.Sprintf("現在の%s: ", names[pintype]))
+		if err != nil {
+			return nil
+		}
+		oldPins[pintype] = pin
+	}
+	newpin, err := inputPin("新しい暗証番号(4桁): ")
+	if err != nil {
+		return nil
+	}
+	newPins := map[string]string{
+		"CARD_INPUT_HELPER": newpin,
+		"JPKI_AUTH":         newpin,
+	}
+	newpass, err := inputPin("新しいJPKI署名用パスワード(6-16文字): ")
+	if err != nil {
+		return nil
+	}
+	if newpass != "" {
+		newPins["JPKI_SIGN"] = newpass
+	}
+
+	results, err := libmyna.ChangeAllPins(oldPins, newPins)
+	if err != nil {
+		return err
+	}
+	var failed bool
+	for _, pintype := range pintypes {
+		err, ok := results[pintype]
+		if !ok {
+			continue
+		}
+		if err != nil {
+			failed = true
+			fmt.Printf("%s: %s\n", names[pintype], err)
+		} else {
+			fmt.Printf("%s: 変更しました\n", names[pintype])
+		}
+	}
+	if failed {
+		return errors.New("変更に失敗したPINがあります")
+	}
+	return nil
+}
+
 func inputPin(prompt string) (string, error) {
 	input, err := gopass.GetPasswdPrompt(prompt, true, os.Stdin, os.Stderr)
 	if err != nil {
@@ -189,4 +256,6 @@ func init() {
 	pinChangeJPKISignCmd.Flags().String("pin", "", "現在のパスワード(6-16文字)")
 	pinChangeJPKISignCmd.Flags().String("newpin", "", "新しいパスワード(6-16文字)")
 	pinChangeCmd.AddCommand(pinChangeJPKISignCmd)
+
+	pinChangeCmd.AddCommand(pinChangeAllCmd)
 }
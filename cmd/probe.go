@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jpki/myna/libmyna"
+)
+
+var probeCmd = &cobra.Command{
+	Use:   "probe",
+	Short: "リーダーとカードの情報を表示",
+	Long: `PC/SCのバージョン、リーダー、ATR、プロトコル、トークン情報、
+各APの選択可否を表示します。
+不具合報告の際に出力を添付してください。
+`,
+	RunE: probe,
+}
+
+func probe(cmd *cobra.Command, args []string) error {
+	report, err := libmyna.Probe()
+	form, _ := cmd.Flags().GetString("form")
+	switch form {
+	case "json":
+		out, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Printf("%s\n", out)
+	default:
+		fmt.Printf("PC/SC:    %s\n", report.PCSCVersion)
+		for i, name := range report.Readers {
+			fmt.Printf("Reader %d: %s\n", i, name)
+		}
+		fmt.Printf("使用中:   %s\n", report.Reader)
+		fmt.Printf("ATR:      %s\n", report.ATR)
+		fmt.Printf("Protocol: %s\n", report.Protocol)
+		fmt.Printf("Token:    %s\n", report.Token)
+		fmt.Printf("Version:  %s\n", report.APVersion)
+		for _, ap := range []string{"visual", "text", "jpki"} {
+			if selectable, ok := report.APs[ap]; ok {
+				fmt.Printf("AP %-6s %v\n", ap+":", selectable)
+			}
+		}
+	}
+	return err
+}
+
+var diagnoseCmd = &cobra.Command{
+	Use:   "diagnose",
+	Short: "カードを読み取れない原因を調べます",
+	Long: `PC/SCサービス、リーダー、カードの挿入、カードの種別を順に確認し、
+最初に失敗した段階と対処方法を表示します。
+`,
+	RunE: diagnose,
+}
+
+func diagnose(cmd *cobra.Command, args []string) error {
+	result := libmyna.Diagnose()
+	for i, name := range result.Readers {
+		fmt.Printf("Reader %d: %s\n", i, name)
+	}
+	if result.Reader != "" {
+		fmt.Printf("Card:     %s (%s)\n", result.Reader, result.CardType)
+	}
+	fmt.Printf("%s\n", result.Stage)
+	if result.Err != nil {
+		fmt.Printf("原因: %s\n", result.Err)
+	}
+	if result.Stage != libmyna.DiagnoseOK {
+		return errors.New("診断に失敗しました")
+	}
+	return nil
+}
+
+func init() {
+	probeCmd.Flags().StringP("form", "f", "text", "出力形式(text,json)")
+}
@@ -9,6 +9,9 @@ import (
 	"github.com/jpki/myna/libmyna"
 )
 
+// --traceで開いたファイル (Executeの終了時に閉じます)
+var traceFile *os.File
+
 var rootCmd = &cobra.Command{
 	Use:     "myna",
 	Version: libmyna.Version,
@@ -23,9 +26,37 @@ Author:
 
 `, libmyna.Version),
 	SilenceUsage: true,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		debug, _ := cmd.Flags().GetBool("debug")
-		libmyna.OptionDebug = libmyna.Debug(debug)
+		lang, _ := cmd.Flags().GetString("lang")
+		if err := libmyna.SetLanguage(lang); err != nil {
+			return err
+		}
+		opts := []func(*libmyna.Reader){libmyna.Debug(debug)}
+		protocol, _ := cmd.Flags().GetString("protocol")
+		proto, err := libmyna.ParseProtocol(protocol)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, libmyna.Protocol(proto))
+		if reader, _ := cmd.Flags().GetString("reader"); reader != "" {
+			opts = append(opts, libmyna.ReaderName(reader))
+		}
+		trace, _ := cmd.Flags().GetString("trace")
+		if trace != "" {
+			file, err := os.Create(trace)
+			if err != nil {
+				return err
+			}
+			traceFile = file
+			opts = append(opts, libmyna.Trace(file))
+		}
+		libmyna.OptionDebug = func(r *libmyna.Reader) {
+			for _, opt := range opts {
+				opt(r)
+			}
+		}
+		return nil
 	},
 }
 
@@ -33,7 +64,14 @@ Author:
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	if traceFile != nil {
+		if cerr := traceFile.Close(); cerr != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", cerr)
+			err = cerr
+		}
+	}
+	if err != nil {
 		os.Exit(1)
 	}
 }
@@ -41,11 +79,21 @@ func Execute() {
 func init() {
 	cobra.EnableCommandSorting = false
 	rootCmd.PersistentFlags().BoolP("debug", "d", false, "デバッグ出力")
+	rootCmd.PersistentFlags().String("trace", "", "APDUの送受信を記録するファイル")
+	rootCmd.PersistentFlags().String("reader", "", "使用するリーダーの名前 (省略時は環境変数MYNA_READER)")
+	rootCmd.PersistentFlags().String("protocol", "any", "接続に使用するプロトコル(any,T0,T1)")
+	rootCmd.PersistentFlags().String("lang", "ja", "エラーメッセージの言語(ja,en)")
 	rootCmd.AddCommand(textCmd)
 	rootCmd.AddCommand(visualCmd)
 	rootCmd.AddCommand(jpkiCmd)
 	rootCmd.AddCommand(pinCmd)
+	rootCmd.AddCommand(verifyCertCmd)
 	rootCmd.AddCommand(testCmd)
+	rootCmd.AddCommand(probeCmd)
+	rootCmd.AddCommand(diagnoseCmd)
+	rootCmd.AddCommand(envCmd)
+	rootCmd.AddCommand(benchCmd)
+	rootCmd.AddCommand(serveCmd)
 }
 
 func checkCard(cmd *cobra.Command, args []string) error {
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jpki/myna/libmyna"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "カードとの接続を保持するサーバーを起動",
+	Long: `カードとの接続を保持したまま、UNIXドメインソケットで
+JSON-RPC(net/rpc/jsonrpc)のリクエストを受け付けます。
+
+利用できるメソッド:
+
+ - Myna.GetMyNumber   {"Pin": "1234"}
+ - Myna.GetAttrInfo   {"Pin": "1234"}
+ - Myna.GetCert       {"Type": "auth|authca|sign|signca", "Pin": "..."}
+ - Myna.SignDigest    {"Pin": "...", "Hash": "sha256", "Digest": "<base64>"}
+`,
+	RunE: serve,
+}
+
+type MynaService struct {
+	session *libmyna.Session
+}
+
+type PinArgs struct {
+	Pin string
+}
+
+type CertArgs struct {
+	Type string
+	Pin  string
+}
+
+type SignArgs struct {
+	Pin    string
+	Hash   string
+	Digest []byte
+}
+
+func (self *MynaService) GetMyNumber(args *PinArgs, reply *string) error {
+	mynumber, err := self.session.GetMyNumber(args.Pin)
+	if err != nil {
+		return err
+	}
+	*reply = mynumber
+	return nil
+}
+
+func (self *MynaService) GetAttrInfo(args *PinArgs, reply *map[string]string) error {
+	attr, err := self.session.GetAttrInfo(args.Pin)
+	if err != nil {
+		return err
+	}
+	*reply = map[string]string{
+		"name":    attr.Name,
+		"address": attr.Address,
+		"birth":   attr.Birth,
+		"sex":     attr.SexString(),
+	}
+	return nil
+}
+
+func (self *MynaService) GetCert(args *CertArgs, reply *[]byte) error {
+	efid, err := self.session.Reader().Profile().CertEF(args.Type)
+	if err != nil {
+		return err
+	}
+	pin := ""
+	if strings.ToUpper(args.Type) == "SIGN" {
+		pin = strings.ToUpper(args.Pin)
+	}
+	data, err := self.session.GetJPKICertRaw(efid, pin)
+	if err != nil {
+		return err
+	}
+	*reply = data
+	return nil
+}
+
+func (self *MynaService) SignDigest(args *SignArgs, reply *[]byte) error {
+	hash, err := libmyna.GetDigestHash(args.Hash)
+	if err != nil {
+		return err
+	}
+	signature, err := self.session.SignDigest(
+		strings.ToUpper(args.Pin), hash, args.Digest)
+	if err != nil {
+		return err
+	}
+	*reply = signature
+	return nil
+}
+
+func serve(cmd *cobra.Command, args []string) error {
+	socket, _ := cmd.Flags().GetString("socket")
+	if socket == "" {
+		var err error
+		socket, err = defaultSocketPath()
+		if err != nil {
+			return err
+		}
+	}
+
+	session, err := libmyna.NewSession(libmyna.OptionDebug)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	server := rpc.NewServer()
+	err = server.RegisterName("Myna", &MynaService{session})
+	if err != nil {
+		return err
+	}
+
+	err = removeStaleSocket(socket)
+	if err != nil {
+		return err
+	}
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+	err = os.Chmod(socket, 0600)
+	if err != nil {
+		return err
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sig
+		listener.Close()
+	}()
+
+	fmt.Fprintf(os.Stderr, "listening on %s\n", socket)
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return nil
+		}
+		go server.ServeCodec(jsonrpc.NewServerCodec(conn))
+	}
+}
+
+// 既定のソケットは本人だけが入れるディレクトリに作成します
+// $XDG_RUNTIME_DIRが無い場合は一時ディレクトリにmyna-<uid>を0700で作成します
+func defaultSocketPath() (string, error) {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "myna.sock"), nil
+	}
+	dir := filepath.Join(os.TempDir(), fmt.Sprintf("myna-%d", os.Getuid()))
+	err := os.Mkdir(dir, 0700)
+	if err != nil && !os.IsExist(err) {
+		return "", err
+	}
+	fi, err := os.Lstat(dir)
+	if err != nil {
+		return "", err
+	}
+	if !fi.IsDir() || fi.Mode().Perm() != 0700 {
+		return "", fmt.Errorf("%sは0700のディレクトリではありません", dir)
+	}
+	return filepath.Join(dir, "myna.sock"), nil
+}
+
+// 前回のソケットが残っていれば削除します
+// ソケット以外のファイルは誤って消さないようにエラーにします
+func removeStaleSocket(socket string) error {
+	fi, err := os.Lstat(socket)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if fi.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%sはソケットではないため削除できません", socket)
+	}
+	return os.Remove(socket)
+}
+
+func init() {
+	serveCmd.Flags().StringP("socket", "s", "",
+		"UNIXドメインソケットのパス (既定は$XDG_RUNTIME_DIR/myna.sock)")
+}
@@ -108,6 +108,38 @@ func findDF(reader *libmyna.Reader, prefix []byte) [][]byte {
 	return tmp
 }
 
+var listEFCmd = &cobra.Command{
+	Use:   "list_ef [jpki|text|visual|AID]",
+	Short: "AP配下のEFを列挙",
+	Long: `AP配下のEF識別子00 00から00 FFをSELECTして存在するEFを表示します
+SELECT以外のコマンドは送信しないためPINがロックされることはありません
+`,
+	RunE: listEF,
+}
+
+func listEF(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		cmd.Help()
+		return nil
+	}
+	efs, err := libmyna.ListEFs(args[0])
+	if err != nil {
+		return err
+	}
+	for _, ef := range efs {
+		size := "-"
+		if ef.Size >= 0 {
+			size = fmt.Sprintf("%d", ef.Size)
+		}
+		pin := ""
+		if ef.Pin {
+			pin = " (PIN)"
+		}
+		fmt.Printf("%s size=%s%s\n", ef.ID, size, pin)
+	}
+	return nil
+}
+
 /*
 func findEF(c *cli.Context, df string) {
 	reader, err := libmyna.Ready(c)
@@ -134,5 +166,6 @@ func init() {
 	toolCmd.AddCommand(beepCmd)
 
 	toolCmd.AddCommand(findAPCmd)
+	toolCmd.AddCommand(listEFCmd)
 	rootCmd.AddCommand(toolCmd)
 }
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"crypto/x509"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jpki/myna/libmyna"
+)
+
+var verifyCertCmd = &cobra.Command{
+	Use:   "verify-cert [sign|auth]",
+	Short: "JPKI証明書の有効性を検証",
+	Long: `公的個人認証の証明書を検証します。
+
+ - sign   電子署名用証明書 (デフォルト)
+ - auth   利用者認証用証明書
+
+カードのCA証明書を信頼点として証明書チェーンと有効期間を検証し、
+--ocsp を指定した場合はOCSPで失効の有無を確認します。
+`,
+	RunE:    verifyCert,
+	PreRunE: checkCard,
+}
+
+func verifyCert(cmd *cobra.Command, args []string) error {
+	var cert *x509.Certificate
+	var err error
+	target := "SIGN"
+	if len(args) > 0 {
+		target = strings.ToUpper(args[0])
+	}
+	switch target {
+	case "SIGN":
+		pin, _ := cmd.Flags().GetString("pin")
+		if pin == "" {
+			pin, err = inputPin("署名用パスワード(6-16桁): ")
+			if err != nil {
+				return nil
+			}
+		}
+		pin = strings.ToUpper(pin)
+		cert, err = libmyna.GetJPKISignCert(pin)
+	case "AUTH":
+		cert, err = libmyna.GetJPKIAuthCert()
+	default:
+		cmd.Usage()
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	useOCSP, _ := cmd.Flags().GetBool("ocsp")
+	result, err := libmyna.VerifyCertificateFull(
+		cert, libmyna.CertVerifyOpts{OCSP: useOCSP})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Subject:  %s\n", libmyna.Name2String(cert.Subject))
+	fmt.Printf("Issuer:   %s\n", libmyna.Name2String(cert.Issuer))
+	fmt.Printf("NotAfter: %s\n", cert.NotAfter)
+	if result.OCSPStatus != "" {
+		fmt.Printf("OCSP:     %s\n", result.OCSPStatus)
+	}
+	if result.OK {
+		fmt.Printf("PASS\n")
+		return nil
+	}
+	fmt.Printf("FAIL\n")
+	for _, reason := range result.Reasons {
+		fmt.Printf("  - %s\n", reason)
+	}
+	return fmt.Errorf("証明書の検証に失敗しました")
+}
+
+func init() {
+	verifyCertCmd.Flags().StringP(
+		"pin", "p", "", "パスワード(署名用証明書のみ)")
+	verifyCertCmd.Flags().Bool("ocsp", false, "OCSPで失効確認を行う")
+}
@@ -27,6 +27,12 @@ func NewAPDUCase2(cla uint8, ins uint8, p1 uint8, p2 uint8, le uint8) *APDU {
 	return &apdu
 }
 
+// 拡張長のLe(2バイト)を持つCase 2のAPDUを作成します (Le=0は65536バイト)
+func NewAPDUCase2Extended(cla uint8, ins uint8, p1 uint8, p2 uint8, le uint16) *APDU {
+	apdu := APDU{[]uint8{cla, ins, p1, p2, 0x00, uint8(le >> 8), uint8(le)}}
+	return &apdu
+}
+
 func NewAPDUCase3(cla uint8, ins uint8, p1 uint8, p2 uint8, data []uint8) *APDU {
 	cmd := append([]uint8{cla, ins, p1, p2, uint8(len(data))}, data...)
 	apdu := APDU{cmd}
@@ -43,3 +49,28 @@ func NewAPDUCase4(cla uint8, ins uint8, p1 uint8, p2 uint8, data []uint8, le uin
 func (self *APDU) ToString() string {
 	return fmt.Sprintf("% X", self.cmd)
 }
+
+// APDU応答のステータスワード (SW1を上位、SW2を下位のバイトとします)
+type StatusWord uint16
+
+const SWSuccess StatusWord = 0x9000
+
+func NewStatusWord(sw1 uint8, sw2 uint8) StatusWord {
+	return StatusWord(sw1)<<8 | StatusWord(sw2)
+}
+
+func (self StatusWord) SW1() uint8 {
+	return uint8(self >> 8)
+}
+
+func (self StatusWord) SW2() uint8 {
+	return uint8(self)
+}
+
+func (self StatusWord) IsSuccess() bool {
+	return self == SWSuccess
+}
+
+func (self StatusWord) String() string {
+	return fmt.Sprintf("%04X", uint16(self))
+}
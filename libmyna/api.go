@@ -3,16 +3,21 @@
 package libmyna
 
 import (
+	"bytes"
+	"context"
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
 	"crypto/x509"
 	"encoding/asn1"
 	"encoding/pem"
 	"errors"
-	"fmt"
 	"io"
 	"io/ioutil"
+	"math/big"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/yu-ichiro/pkcs7"
 )
@@ -30,26 +35,29 @@ func CheckCard() error {
 
 	jpkiAP, err := reader.SelectJPKIAP()
 	if err != nil {
-		return errors.New("個人番号カードではありません")
+		if errors.Is(err, ErrAPNotFound) {
+			return err
+		}
+		return newError("NOT_MYNUMBER_CARD", err)
 	}
 
-	err = reader.SelectEF("00 06")
+	err = reader.SelectEF(reader.profile.TokenEF)
 	if err != nil {
-		return errors.New("トークン情報を取得できません")
+		return newError("TOKEN_UNREADABLE", err)
 	}
 
 	token, err := jpkiAP.GetToken()
 	if token == "JPKIAPICCTOKEN2" {
 		return nil
 	} else if token == "JPKIAPICCTOKEN" {
-		return errors.New("これは住基カードですね?")
+		return newError("JUKI_CARD", nil)
 	} else {
-		return fmt.Errorf("不明なトークン情報: %s", token)
+		return newError("UNKNOWN_TOKEN", nil, token)
 	}
 }
 
-// 券面入力補助APのマイナンバーを取得します
-func GetMyNumber(pin string) (string, error) {
+// JPKI APの版を返します。判定できない場合は空文字列を返します
+func GetJPKIAPVersion() (string, error) {
 	reader, err := NewReader(OptionDebug)
 	if err != nil {
 		return "", err
@@ -59,44 +67,254 @@ func GetMyNumber(pin string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	textAP, err := reader.SelectTextAP()
+	jpkiAP, err := reader.SelectJPKIAP()
 	if err != nil {
 		return "", err
 	}
-	err = textAP.VerifyPin(pin)
+	return jpkiAP.GetVersion()
+}
+
+// JPKI APのSELECTのみでマイナンバーカードかどうかを判定します
+// トークン情報を読まないため住基カードとは区別できません。厳密な判定にはCheckCardを使ってください
+func IsMyNumberCard() (bool, error) {
+	reader, err := NewReader(OptionDebug)
+	if err != nil {
+		return false, err
+	}
+	defer reader.Finalize()
+	err = reader.Connect()
 	if err != nil {
-		return "", err
+		return false, err
 	}
+	_, err = reader.SelectJPKIAP()
+	if errors.Is(err, ErrAPNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
 
-	mynumber, err := textAP.ReadMyNumber()
+// 署名用電子証明書が発行されているかをパスワードなしで判定します
+func HasSignCert() (bool, error) {
+	reader, err := NewReader(OptionDebug)
 	if err != nil {
-		return "", err
+		return false, err
+	}
+	defer reader.Finalize()
+	err = reader.Connect()
+	if err != nil {
+		return false, err
+	}
+	jpkiAP, err := reader.SelectJPKIAP()
+	if err != nil {
+		return false, err
 	}
-	return mynumber, nil
+	return jpkiAP.HasSignCert()
 }
 
-// 券面入力補助APの4属性情報を取得します
-func GetAttrInfo(pin string) (*TextAttrs, error) {
+// 署名用パスワードが設定されているかを、試行回数を消費せずに判定します
+func IsSignPinInitialized() (bool, error) {
 	reader, err := NewReader(OptionDebug)
 	if err != nil {
-		return nil, err
+		return false, err
 	}
 	defer reader.Finalize()
 	err = reader.Connect()
+	if err != nil {
+		return false, err
+	}
+	jpkiAP, err := reader.SelectJPKIAP()
+	if err != nil {
+		return false, err
+	}
+	return jpkiAP.IsSignPinInitialized()
+}
+
+// カードの挿入を待ってカードの種別を判定します
+func WaitAndIdentify(ctx context.Context) (CardType, error) {
+	reader, err := NewReader(OptionDebug)
+	if err != nil {
+		return CardTypeUnknown, err
+	}
+	defer reader.Finalize()
+	err = reader.WaitForCard(ctx)
+	if err != nil {
+		return CardTypeUnknown, err
+	}
+	return reader.IdentifyCard()
+}
+
+// カードの挿入を待ってfnを呼び出し、カードが取り出されたら次のカードを待つことを繰り返します
+// ctxがキャンセルされるとctx.Err()を、fnがエラーを返した場合はそのエラーを返して終了します
+// fnに渡したSessionは次のカードでも使い回すため、fnの中でCloseしないでください
+func ForEachCard(ctx context.Context, fn func(*Session) error) error {
+	reader, err := NewReader(OptionDebug)
+	if err != nil {
+		return err
+	}
+	defer reader.Finalize()
+	session := Session{reader: reader}
+	for {
+		err = reader.WaitForCard(ctx)
+		if err != nil {
+			return err
+		}
+		err = fn(&session)
+		if err != nil {
+			return err
+		}
+		err = reader.WaitForRemoval(ctx)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// 操作がctxのキャンセルや期限で中断された場合はエラーをctx.Err()に置き換えます
+func contextError(ctx context.Context, err error) error {
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// 券面入力補助APのマイナンバーを取得します
+func GetMyNumber(pin string) (string, error) {
+	return GetMyNumberContext(context.Background(), pin)
+}
+
+// GetMyNumberと同様ですが、ctxのキャンセルや期限でカードの待機と通信を中断します
+func GetMyNumberContext(ctx context.Context, pin string) (string, error) {
+	session, err := NewSession(OptionDebug, CancelContext(ctx))
+	if err != nil {
+		return "", contextError(ctx, err)
+	}
+	defer session.Close()
+	mynumber, err := session.GetMyNumber(pin)
+	return mynumber, contextError(ctx, err)
+}
+
+type MyNumberRecord struct {
+	MyNumber   string
+	Valid      bool // 検査用数字の検証結果
+	CapturedAt time.Time
+}
+
+// 券面入力補助APのマイナンバーを検査用数字の検証結果と読み取り時刻と共に取得します
+func GetMyNumberRecord(pin string) (*MyNumberRecord, error) {
+	mynumber, err := GetMyNumber(pin)
 	if err != nil {
 		return nil, err
 	}
+	record := MyNumberRecord{
+		MyNumber:   mynumber,
+		Valid:      ValidateMyNumber(mynumber) == nil,
+		CapturedAt: time.Now(),
+	}
+	return &record, nil
+}
 
-	textAP, err := reader.SelectTextAP()
+// 券面入力補助APの住所から市区町村の地方公共団体コードと団体名を取得します
+// カードに市区町村コードは無いため住所から推定します。判定できない場合、コードは空文字列です
+func GetIssuingMunicipality(pin string) (string, string, error) {
+	session, err := NewSession(OptionDebug)
+	if err != nil {
+		return "", "", err
+	}
+	defer session.Close()
+	return session.GetIssuingMunicipality(pin)
+}
+
+// 券面入力補助APの4属性情報を取得します
+func GetAttrInfo(pin string) (*TextAttrs, error) {
+	return GetAttrInfoContext(context.Background(), pin)
+}
+
+// GetAttrInfoと同様ですが、ctxのキャンセルや期限でカードの待機と通信を中断します
+func GetAttrInfoContext(ctx context.Context, pin string) (*TextAttrs, error) {
+	session, err := NewSession(OptionDebug, CancelContext(ctx))
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+	defer session.Close()
+	attrs, err := session.GetAttrInfo(pin)
+	return attrs, contextError(ctx, err)
+}
+
+// 券面入力補助APの基本4情報のEFをTLVの構造のまま取得します
+func DumpAttrDER(pin string) ([]TLV, error) {
+	session, err := NewSession(OptionDebug)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+	return session.DumpAttrDER(pin)
+}
+
+type Identity struct {
+	MyNumber string
+	Attrs    *TextAttrs
+}
+
+// 同じカードから1回のPIN照合でマイナンバーと基本4情報を取得します
+func GetIdentity(pin string) (*Identity, error) {
+	session, err := NewSession(OptionDebug)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+	return session.GetIdentity(pin)
+}
+
+// 基本4情報の生年月日と、オフラインで真正性を検証するための署名と証明書
+type SignedBirthDate struct {
+	Birth       string
+	Attrs       []byte // 基本4情報のEFのDER (署名の対象)
+	Signature   *TextSignature
+	Certificate *TextCertificate
+}
+
+// 券面事項入力補助APと署名用証明書の基本4情報を照合します
+func VerifyFullIdentity(signPin string, helperPin string) (*FullIdentityReport, error) {
+	session, err := NewSession(OptionDebug)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+	return session.VerifyFullIdentity(signPin, helperPin)
+}
+
+// 生年月日を署名と証明書と共に取得します
+func GetVerifiableBirthDate(pin string) (*SignedBirthDate, error) {
+	session, err := NewSession(OptionDebug)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+	return session.GetVerifiableBirthDate(pin)
+}
+
+// Webログイン用に、サーバーのnonceと発行時刻・利用者証明用証明書を含む
+// JWT形式(RS256)のアサーションを作成します
+// サーバー側ではParseLoginAssertionで署名を検証できます
+func SignLoginAssertion(pin string, nonce []byte) ([]byte, error) {
+	session, err := NewSession(OptionDebug)
 	if err != nil {
 		return nil, err
 	}
-	err = textAP.VerifyPin(pin)
+	defer session.Close()
+	return session.SignLoginAssertion(pin, nonce)
+}
+
+// 利用者の登録に必要な証明書と基本4情報を1回の接続で取得します
+func Provision(signPin string, helperPin string) (*ProvisionBundle, error) {
+	session, err := NewSession(OptionDebug)
 	if err != nil {
 		return nil, err
 	}
-	attr, err := textAP.ReadAttributes()
-	return attr, err
+	defer session.Close()
+	return session.Provision(signPin, helperPin)
 }
 
 type CardInfo struct {
@@ -140,6 +358,9 @@ func ChangeJPKIAuthPin(pin string, newpin string) error {
 }
 
 func Change4DigitPin(pin string, newpin string, pintype string) error {
+	if err := checkReadOnly(); err != nil {
+		return err
+	}
 
 	err := Validate4DigitPin(pin)
 	if err != nil {
@@ -163,38 +384,57 @@ func Change4DigitPin(pin string, newpin string, pintype string) error {
 
 	switch pintype {
 	case "CARD_INPUT_HELPER":
-		reader.SelectTextAP()
-		reader.SelectEF("0011") // 券面入力補助PIN
+		err = reader.AuthenticateAP("TEXT", "0011", pin) // 券面入力補助PIN
 	case "JPKI_AUTH":
-		reader.SelectJPKIAP()
-		reader.SelectEF("0018") //JPKI認証用PIN
+		err = reader.AuthenticateAP("JPKI", reader.profile.AuthPinEF, pin) //JPKI認証用PIN
+	default:
+		err = newError("UNKNOWN_PIN_TYPE", nil, pintype)
 	}
-
-	err = reader.Verify(pin)
 	if err != nil {
 		return err
 	}
 
-	res := reader.ChangePin(newpin)
-	if !res {
-		return errors.New("PINの変更に失敗しました")
+	err = reader.changePin(newpin)
+	if err != nil {
+		return newError("PIN_CHANGE_FAILED", err)
 	}
 	return nil
 }
 
-func ChangeJPKISignPin(pin string, newpin string) error {
-	pin = strings.ToUpper(pin)
-	err := ValidateJPKISignPassword(pin)
+// PINを変更せずに照合のみ行います
+// pintypeはGetPinRetryCountと同じです。照合に失敗した場合は残り回数を含むエラーを返します
+func VerifyPin(pintype string, pin string) error {
+	pin, err := checkPinFormat(pintype, pin)
 	if err != nil {
 		return err
 	}
+	return verifyPin(pintype, pin, false)
+}
 
-	newpin = strings.ToUpper(newpin)
-	err = ValidateJPKISignPassword(newpin)
+// 残り試行回数が1回以下の場合は照合を行わずにErrWouldLockを返します
+// 照合の失敗でカードがロックされることを防ぎます
+func SafeVerify(pintype string, pin string) error {
+	pin, err := checkPinFormat(pintype, pin)
 	if err != nil {
 		return err
 	}
+	return verifyPin(pintype, pin, true)
+}
+
+// PINの種別に応じて形式を検証し、署名用パスワードは大文字にして返します
+func checkPinFormat(pintype string, pin string) (string, error) {
+	switch pintype {
+	case "CARD_INPUT_HELPER", "JPKI_AUTH":
+		return pin, Validate4DigitPin(pin)
+	case "JPKI_SIGN":
+		pin = strings.ToUpper(pin)
+		return pin, ValidateJPKISignPassword(pin)
+	default:
+		return "", newError("UNKNOWN_PIN_TYPE", nil, pintype)
+	}
+}
 
+func verifyPin(pintype string, pin string, safe bool) error {
 	reader, err := NewReader(OptionDebug)
 	if err != nil {
 		return err
@@ -204,114 +444,405 @@ func ChangeJPKISignPin(pin string, newpin string) error {
 	if err != nil {
 		return err
 	}
+	return verifyReaderPin(reader, pintype, pin, safe)
+}
 
-	reader.SelectJPKIAP()
-	reader.SelectEF("00 1B") // IEF for SIGN
+func verifyReaderPin(reader *Reader, pintype string, pin string, safe bool) error {
+	var lookup func() (int, error)
+	var verify func(string) error
+	switch pintype {
+	case "CARD_INPUT_HELPER":
+		textAP, err := reader.SelectTextAP()
+		if err != nil {
+			return err
+		}
+		lookup, verify = textAP.LookupPin, textAP.VerifyPin
+	case "JPKI_AUTH":
+		jpkiAP, err := reader.SelectJPKIAP()
+		if err != nil {
+			return err
+		}
+		lookup, verify = jpkiAP.LookupAuthPin, jpkiAP.VerifyAuthPin
+	default:
+		jpkiAP, err := reader.SelectJPKIAP()
+		if err != nil {
+			return err
+		}
+		lookup, verify = jpkiAP.LookupSignPin, jpkiAP.VerifySignPin
+	}
 
-	err = reader.Verify(pin)
+	if safe {
+		count, err := lookup()
+		if err != nil {
+			return err
+		}
+		if count < 0 {
+			return newError("PIN_RETRY_UNKNOWN", nil)
+		}
+		if !isSafeRetryCount(count) {
+			return newError("WOULD_LOCK", nil, count)
+		}
+	}
+	return verify(pin)
+}
+
+// newPinsに指定した種別のPINをまとめて変更します
+// 種別はGetPinRetryCountと同じです。いずれかの変更に失敗しても残りの変更を続け、
+// 種別ごとの結果を返します。PINの形式が正しくない場合は何も変更せずにエラーを返します
+func ChangeAllPins(oldPins map[string]string, newPins map[string]string) (map[string]error, error) {
+	if err := checkReadOnly(); err != nil {
+		return nil, err
+	}
+	for pintype, newpin := range newPins {
+		if _, err := checkPinFormat(pintype, oldPins[pintype]); err != nil {
+			return nil, err
+		}
+		if _, err := checkPinFormat(pintype, newpin); err != nil {
+			return nil, err
+		}
+	}
+
+	results := map[string]error{}
+	for _, pintype := range []string{"CARD_INPUT_HELPER", "JPKI_AUTH", "JPKI_SIGN"} {
+		newpin, ok := newPins[pintype]
+		if !ok {
+			continue
+		}
+		if pintype == "JPKI_SIGN" {
+			results[pintype] = ChangeJPKISignPin(oldPins[pintype], newpin)
+		} else {
+			results[pintype] = Change4DigitPin(oldPins[pintype], newpin, pintype)
+		}
+	}
+	return results, nil
+}
+
+func ChangeJPKISignPin(pin string, newpin string) error {
+	if err := checkReadOnly(); err != nil {
+		return err
+	}
+	pin = strings.ToUpper(pin)
+	err := ValidateJPKISignPassword(pin)
 	if err != nil {
 		return err
 	}
 
-	res := reader.ChangePin(newpin)
-	if !res {
-		return errors.New("PINの変更に失敗しました")
+	newpin = strings.ToUpper(newpin)
+	err = ValidateJPKISignPassword(newpin)
+	if err != nil {
+		return err
 	}
-	return nil
-}
 
-func GetJPKICert(efid string, pin string) (*x509.Certificate, error) {
 	reader, err := NewReader(OptionDebug)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer reader.Finalize()
 	err = reader.Connect()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	jpkiAP, err := reader.SelectJPKIAP()
+	err = reader.AuthenticateAP("JPKI", reader.profile.SignPinEF, pin) // IEF for SIGN
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	if pin != "" {
-		err = jpkiAP.VerifySignPin(pin)
-		if err != nil {
-			return nil, err
-		}
+	err = reader.changePin(newpin)
+	if err != nil {
+		return newError("PIN_CHANGE_FAILED", err)
 	}
-	cert, err := jpkiAP.ReadCertificate(efid)
-	return cert, nil
+	return nil
 }
 
-func GetJPKIAuthCert() (*x509.Certificate, error) {
-	return GetJPKICert("00 0A", "")
+func GetJPKICert(efid string, pin string) (*x509.Certificate, error) {
+	return GetJPKICertContext(context.Background(), efid, pin)
 }
 
-func GetJPKIAuthCACert() (*x509.Certificate, error) {
-	return GetJPKICert("00 0B", "")
+// GetJPKICertと同様ですが、ctxのキャンセルや期限でカードの待機と通信を中断します
+func GetJPKICertContext(ctx context.Context, efid string, pin string) (*x509.Certificate, error) {
+	data, err := getJPKICertRaw(ctx, efid, CertReadOpts{Pin: pin})
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(data)
 }
 
-func GetJPKISignCert(pass string) (*x509.Certificate, error) {
-	return GetJPKICert("00 01", pass)
+// カードから読み取った証明書のDERを再エンコードせずに返します
+func GetJPKICertRaw(efid string, pin string) ([]byte, error) {
+	return GetJPKICertRawWithOpts(efid, CertReadOpts{Pin: pin})
 }
 
-func GetJPKISignCACert() (*x509.Certificate, error) {
-	return GetJPKICert("00 02", "")
+type CertReadOpts struct {
+	Pin      string       // 署名用証明書の場合は署名用パスワード
+	Progress ProgressFunc // 読み取りの進捗の通知先 (nilの場合は通知しません)
 }
 
-/*
-func CmsSignJPKISignOld(pin string, in string, out string) error {
-	rawContent, err := ioutil.ReadFile(in)
+func GetJPKICertWithOpts(efid string, opts CertReadOpts) (*x509.Certificate, error) {
+	data, err := GetJPKICertRawWithOpts(efid, opts)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return x509.ParseCertificate(data)
+}
 
-	toBeSigned, err := pkcs7.NewSignedData(rawContent)
+func GetJPKICertRawWithOpts(efid string, opts CertReadOpts) ([]byte, error) {
+	return getJPKICertRaw(context.Background(), efid, opts)
+}
+
+func getJPKICertRaw(ctx context.Context, efid string, opts CertReadOpts) ([]byte, error) {
+	session, err := NewSession(OptionDebug, CancelContext(ctx))
 	if err != nil {
-		return err
+		return nil, contextError(ctx, err)
 	}
+	defer session.Close()
+	data, err := session.GetJPKICertRawWithOpts(efid, opts)
+	return data, contextError(ctx, err)
+}
 
-	// 署名用証明書の取得
-	cert, err := GetJPKISignCert(pin)
+// 券面事項入力補助APの証明書(EF 00 04)を読み取ります
+// この証明書はX.509ではなくカード検証可能証明書(CV証明書)のため、
+// x509.Certificateとしては扱えません。pinが空でない場合は照合してから読み取ります
+func GetAttrSignCert(pin string) (*TextCertificate, error) {
+	reader, err := NewReader(OptionDebug)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	attrs, hashed, err := toBeSigned.HashAttributes(crypto.SHA1, pkcs7.SignerInfoConfig{})
+	defer reader.Finalize()
+	err = reader.Connect()
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	ias, err := pkcs7.Cert2issuerAndSerial(cert)
+	textAP, err := reader.SelectTextAP()
 	if err != nil {
-		return err
+		return nil, err
 	}
+	if pin != "" {
+		err = textAP.VerifyPin(pin)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return textAP.ReadCertificate()
+}
 
+// PinProviderから取得したパスワードで署名用証明書を読み取ります
+func GetJPKISignCertWithPinProvider(provider PinProvider) (*x509.Certificate, error) {
 	reader, err := NewReader(OptionDebug)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer reader.Finalize()
 	err = reader.Connect()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	reader.SelectJPKIAP()
-	reader.SelectEF("00 1B") // IEF for SIGN
-	err = reader.Verify(pin)
+	jpkiAP, err := reader.SelectJPKIAP()
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	reader.SelectEF("00 1A") // Select SIGN EF
-	digestInfo := makeDigestInfo(hashed)
-
-	signature, err := reader.Signature(digestInfo)
+	err = jpkiAP.VerifySignPinWithProvider(provider)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	return jpkiAP.ReadCertificate(reader.profile.SignCertEF)
+}
+
+// 証明書とそのCA証明書を1回の接続で読み取ります
+func GetJPKICertChain(efid string, caEfid string, pin string) ([]*x509.Certificate, error) {
+	return GetJPKICertChainContext(context.Background(), efid, caEfid, pin)
+}
+
+// GetJPKICertChainと同様ですが、ctxのキャンセルや期限でカードの待機と通信を中断します
+func GetJPKICertChainContext(ctx context.Context, efid string, caEfid string, pin string) ([]*x509.Certificate, error) {
+	reader, err := NewReader(OptionDebug, CancelContext(ctx))
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+	defer reader.Finalize()
+	err = reader.Connect()
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+
+	jpkiAP, err := reader.SelectJPKIAP()
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+
+	if pin != "" {
+		err = jpkiAP.VerifySignPin(pin)
+		if err != nil {
+			return nil, contextError(ctx, err)
+		}
+	}
+	cert, err := jpkiAP.ReadCertificate(efid)
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+	cacert, err := jpkiAP.ReadCertificate(caEfid)
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+	return []*x509.Certificate{cert, cacert}, nil
+}
+
+func GetJPKIAuthCertChain() ([]*x509.Certificate, error) {
+	return GetJPKICertChain(DefaultCardProfile.AuthCertEF,
+		DefaultCardProfile.AuthCACertEF, "")
+}
+
+func GetJPKISignCertChain(pass string) ([]*x509.Certificate, error) {
+	return GetJPKICertChain(DefaultCardProfile.SignCertEF,
+		DefaultCardProfile.SignCACertEF, pass)
+}
+
+// 証明書チェーンをPEM形式で連結して出力します
+// chainは末端の証明書から順に並べてください
+func WriteCertChainPEM(w io.Writer, chain []*x509.Certificate) error {
+	for _, cert := range chain {
+		err := pem.Encode(w, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// カードの利用者証明用証明書がJPKIRootsまで検証できるか確認します
+// CheckCardのトークンの比較と異なり、証明書の署名を検証します
+func VerifyCardAuthenticity() (bool, error) {
+	if JPKIRoots == nil {
+		return false, newError("ROOTS_NOT_CONFIGURED", nil)
+	}
+	session, err := NewSession(OptionDebug)
+	if err != nil {
+		return false, err
+	}
+	defer session.Close()
+	return session.VerifyCardAuthenticity(JPKIRoots)
+}
+
+// 外部のライブラリでCMSを組み立てるために、署名対象の属性とその署名値、署名用証明書を返します
+func HashAndSign(pin string, content []byte, hash crypto.Hash) ([]byte, []byte, *x509.Certificate, error) {
+	session, err := NewSession(OptionDebug)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer session.Close()
+	return session.HashAndSign(pin, content, hash)
+}
+
+// 証明書チェーンを署名者の無いSignedData(certs-only)としてoutに出力します
+// formには"der"または"pem"を指定します。outが空の場合は標準出力に出力します
+func ExportCertChainP7B(chain []*x509.Certificate, out string, form string) error {
+	if len(chain) == 0 {
+		return newError("EMPTY_CHAIN", nil)
+	}
+	var certs []byte
+	for _, cert := range chain {
+		certs = append(certs, cert.Raw...)
+	}
+	p7b, err := pkcs7.DegenerateCertificate(certs)
+	if err != nil {
+		return err
+	}
+	return writeCms(out, p7b, form, 0)
+}
+
+func GetJPKIAuthCert() (*x509.Certificate, error) {
+	return GetJPKICert(DefaultCardProfile.AuthCertEF, "")
+}
+
+// 利用者証明用証明書をdirにキャッシュして取得します (Session.GetJPKIAuthCertCachedを参照)
+func GetJPKIAuthCertCached(dir string) (*x509.Certificate, error) {
+	session, err := NewSession(OptionDebug)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+	return session.GetJPKIAuthCertCached(dir)
+}
+
+func GetJPKIAuthCACert() (*x509.Certificate, error) {
+	return GetJPKICert(DefaultCardProfile.AuthCACertEF, "")
+}
+
+func GetJPKISignCert(pass string) (*x509.Certificate, error) {
+	return GetJPKICert(DefaultCardProfile.SignCertEF, pass)
+}
+
+func GetJPKISignCACert() (*x509.Certificate, error) {
+	return GetJPKICert(DefaultCardProfile.SignCACertEF, "")
+}
+
+func GetJPKIAuthCertContext(ctx context.Context) (*x509.Certificate, error) {
+	return GetJPKICertContext(ctx, DefaultCardProfile.AuthCertEF, "")
+}
+
+func GetJPKIAuthCACertContext(ctx context.Context) (*x509.Certificate, error) {
+	return GetJPKICertContext(ctx, DefaultCardProfile.AuthCACertEF, "")
+}
+
+func GetJPKISignCertContext(ctx context.Context, pass string) (*x509.Certificate, error) {
+	return GetJPKICertContext(ctx, DefaultCardProfile.SignCertEF, pass)
+}
+
+func GetJPKISignCACertContext(ctx context.Context) (*x509.Certificate, error) {
+	return GetJPKICertContext(ctx, DefaultCardProfile.SignCACertEF, "")
+}
+
+/*
+func CmsSignJPKISignOld(pin string, in string, out string) error {
+	rawContent, err := ioutil.ReadFile(in)
+	if err != nil {
+		return err
+	}
+
+	toBeSigned, err := pkcs7.NewSignedData(rawContent)
+	if err != nil {
+		return err
+	}
+
+	// 署名用証明書の取得
+	cert, err := GetJPKISignCert(pin)
+	if err != nil {
+		return err
+	}
+	attrs, hashed, err := toBeSigned.HashAttributes(crypto.SHA1, pkcs7.SignerInfoConfig{})
+	if err != nil {
+		return err
+	}
+
+	ias, err := pkcs7.Cert2issuerAndSerial(cert)
+	if err != nil {
+		return err
+	}
+
+	reader, err := NewReader(OptionDebug)
+	if err != nil {
+		return err
+	}
+	defer reader.Finalize()
+	err = reader.Connect()
+	if err != nil {
+		return err
+	}
+
+	reader.SelectJPKIAP()
+	reader.SelectEF("00 1B") // IEF for SIGN
+	err = reader.Verify(pin)
+	if err != nil {
+		return err
+	}
+
+	reader.SelectEF("00 1A") // Select SIGN EF
+	digestInfo := makeDigestInfo(hashed)
+
+	signature, err := reader.Signature(digestInfo)
+	if err != nil {
+		return err
 	}
 
 	oidDigestAlgorithmSHA1 := asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
@@ -339,8 +870,36 @@ func CmsSignJPKISignOld(pin string, in string, out string) error {
 */
 
 type JPKISignSigner struct {
-	pin    string
-	pubkey crypto.PublicKey
+	pin         string
+	pinProvider PinProvider
+	pubkey      crypto.PublicKey
+	ctx         context.Context // nilの場合は中断しません
+	transcript  *bytes.Buffer   // EnableTranscriptで有効にした場合のみ
+
+	// 指定した場合は署名する接続で証明書を読み直し、一致しなければ署名しません
+	// 確認した証明書と別のカードで署名しないようにするためです
+	cert *x509.Certificate
+}
+
+// 署名の都度PinProviderからパスワードを取得するSignerを作成します
+func NewJPKISignSigner(provider PinProvider, pubkey crypto.PublicKey) *JPKISignSigner {
+	return &JPKISignSigner{pinProvider: provider, pubkey: pubkey}
+}
+
+// Signの都度、カードと送受信したAPDUを記録するようにします
+// 記録はTraceと同じ形式で、PINはXXに置き換えます。FileTransportで再生できます
+// 記録は署名ごとに作り直すため、複数のgoroutineから同時にSignを呼ばないでください
+func (self *JPKISignSigner) EnableTranscript() {
+	self.transcript = &bytes.Buffer{}
+}
+
+// 直前のSignで送受信したAPDUの記録を返します
+// EnableTranscriptを呼んでいない場合はnilを返します
+func (self JPKISignSigner) Transcript() []byte {
+	if self.transcript == nil {
+		return nil
+	}
+	return append([]byte{}, self.transcript.Bytes()...)
 }
 
 func (self JPKISignSigner) Public() crypto.PublicKey {
@@ -348,8 +907,19 @@ func (self JPKISignSigner) Public() crypto.PublicKey {
 }
 
 func (self JPKISignSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) (signature []byte, err error) {
-	digestInfo := makeDigestInfo(opts.HashFunc(), digest)
-	reader, err := NewReader(OptionDebug)
+	if err := checkReadOnly(); err != nil {
+		return nil, err
+	}
+	digestInfo, err := signerDigestInfo(digest, opts)
+	if err != nil {
+		return nil, err
+	}
+	readerOpts := []func(*Reader){OptionDebug, CancelContext(self.ctx)}
+	if self.transcript != nil {
+		self.transcript.Reset()
+		readerOpts = append(readerOpts, teeTrace(self.transcript))
+	}
+	reader, err := NewReader(readerOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -358,120 +928,485 @@ func (self JPKISignSigner) Sign(rand io.Reader, digest []byte, opts crypto.Signe
 	if err != nil {
 		return nil, err
 	}
-	reader.SelectJPKIAP()
-	reader.SelectEF("00 1B") // IEF for SIGN
-	err = reader.Verify(self.pin)
+	jpkiAP, err := reader.SelectJPKIAP()
 	if err != nil {
 		return nil, err
 	}
+	return self.signWithSignKey(jpkiAP, digestInfo)
+}
 
-	reader.SelectEF("00 1A") // Select SIGN EF
-	signature, err = reader.Signature(digestInfo)
+// PINを照合した接続のまま、確認した証明書かを確かめてから署名します
+func (self JPKISignSigner) signWithSignKey(jpkiAP *JPKIAP, digestInfo []byte) ([]byte, error) {
+	var err error
+	if self.pinProvider != nil {
+		err = jpkiAP.VerifySignPinWithProvider(self.pinProvider)
+	} else {
+		err = jpkiAP.VerifySignPin(self.pin)
+	}
 	if err != nil {
 		return nil, err
 	}
-	return signature, nil
+	if self.cert != nil {
+		cert, err := jpkiAP.ReadCertificate(jpkiAP.reader.profile.SignCertEF)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(cert.Raw, self.cert.Raw) {
+			return nil, ErrSignCertMismatch
+		}
+	}
+	return jpkiAP.SignWithSignKey(digestInfo)
+}
+
+// 利用者証明用秘密鍵で署名するcrypto.Signer
+// ログイン用のアサーションなど、ハッシュ済みの値(SHA-256なら32バイト)に署名する用途に使います
+type JPKIAuthSigner struct {
+	pinProvider PinProvider
+	cert        *x509.Certificate
+	ctx         context.Context // nilの場合は中断しません
+}
+
+// 署名の都度PinProviderから利用者証明用PINを取得するSignerを作成します
+// certには利用者証明用証明書(GetJPKIAuthCertで取得したもの)を指定します
+func NewJPKIAuthSigner(provider PinProvider, cert *x509.Certificate) *JPKIAuthSigner {
+	return &JPKIAuthSigner{pinProvider: provider, cert: cert}
+}
+
+// NewJPKIAuthSignerと同じですが、ctxが終了するとカードとの通信を中断します
+func NewJPKIAuthSignerContext(ctx context.Context, provider PinProvider, cert *x509.Certificate) *JPKIAuthSigner {
+	return &JPKIAuthSigner{pinProvider: provider, cert: cert, ctx: ctx}
+}
+
+func (self JPKIAuthSigner) Public() crypto.PublicKey {
+	return self.cert.PublicKey
+}
+
+// 署名の検証に使う利用者証明用証明書を返します
+func (self JPKIAuthSigner) Certificate() *x509.Certificate {
+	return self.cert
+}
+
+func (self JPKIAuthSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if err := checkReadOnly(); err != nil {
+		return nil, err
+	}
+	digestInfo, err := signerDigestInfo(digest, opts)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := NewReader(OptionDebug, CancelContext(self.ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Finalize()
+	err = reader.Connect()
+	if err != nil {
+		return nil, err
+	}
+	jpkiAP, err := reader.SelectJPKIAP()
+	if err != nil {
+		return nil, err
+	}
+	return signWithAuthKey(jpkiAP, self.pinProvider, digestInfo)
+}
+
+func signWithAuthKey(jpkiAP *JPKIAP, provider PinProvider, digestInfo []byte) ([]byte, error) {
+	err := jpkiAP.VerifyAuthPinWithProvider(provider)
+	if err != nil {
+		return nil, err
+	}
+	return jpkiAP.SignWithAuthKey(digestInfo)
+}
+
+type digestAlgorithm struct {
+	name string
+	oid  asn1.ObjectIdentifier
+	hash crypto.Hash
+}
+
+var digestAlgorithms = []digestAlgorithm{
+	{"sha1", pkcs7.OIDDigestAlgorithmSHA1, crypto.SHA1},
+	{"sha256", pkcs7.OIDDigestAlgorithmSHA256, crypto.SHA256},
+	{"sha384", pkcs7.OIDDigestAlgorithmSHA384, crypto.SHA384},
+	{"sha512", pkcs7.OIDDigestAlgorithmSHA512, crypto.SHA512},
+}
+
+// サポートしているダイジェストアルゴリズム名の一覧を返します
+func SupportedDigests() []string {
+	var names []string
+	for _, alg := range digestAlgorithms {
+		names = append(names, alg.name)
+	}
+	return names
+}
+
+func lookupDigest(md string) (*digestAlgorithm, error) {
+	for _, alg := range digestAlgorithms {
+		if strings.EqualFold(alg.name, md) {
+			return &alg, nil
+		}
+	}
+	return nil, newError("UNSUPPORTED_DIGEST", nil,
+		md, strings.Join(SupportedDigests(), ", "))
 }
 
 func GetDigestOID(md string) (asn1.ObjectIdentifier, error) {
-	switch strings.ToUpper(md) {
-	case "SHA1":
-		return pkcs7.OIDDigestAlgorithmSHA1, nil
-	case "SHA256":
-		return pkcs7.OIDDigestAlgorithmSHA256, nil
-	case "SHA384":
-		return pkcs7.OIDDigestAlgorithmSHA384, nil
-	case "SHA512":
-		return pkcs7.OIDDigestAlgorithmSHA512, nil
-	default:
-		return nil, fmt.Errorf("サポートされていないハッシュアルゴリズムです: %s", md)
+	alg, err := lookupDigest(md)
+	if err != nil {
+		return nil, err
+	}
+	return alg.oid, nil
+}
+
+// 署名用証明書の公開鍵の強度に見合うダイジェストアルゴリズム名を返します
+// 現在の署名用証明書(RSA 2048ビット)ではsha256です
+// 衝突耐性の弱いsha1は自動では選択しません
+func DefaultDigestForKey(pubkey crypto.PublicKey) string {
+	switch key := pubkey.(type) {
+	case *rsa.PublicKey:
+		switch bits := key.N.BitLen(); {
+		case bits >= 7680:
+			return "sha512"
+		case bits > 3072:
+			return "sha384"
+		}
+	case *ecdsa.PublicKey:
+		switch key.Curve.Params().BitSize {
+		case 384:
+			return "sha384"
+		case 521:
+			return "sha512"
+		}
+	}
+	return "sha256"
+}
+
+// mdが空の場合は証明書の鍵からダイジェストアルゴリズムを選択します
+func resolveDigest(md string, cert *x509.Certificate) (*digestAlgorithm, error) {
+	if md == "" {
+		md = DefaultDigestForKey(cert.PublicKey)
+	}
+	return lookupDigest(md)
+}
+
+func GetDigestHash(md string) (crypto.Hash, error) {
+	alg, err := lookupDigest(md)
+	if err != nil {
+		return 0, err
+	}
+	return alg.hash, nil
+}
+
+// カードを使わずに署名対象のダイジェスト値を計算します
+func ContentDigest(r io.Reader, hash string) ([]byte, error) {
+	alg, err := lookupDigest(hash)
+	if err != nil {
+		return nil, err
 	}
+	return streamDigest(r, alg.hash)
 }
 
 type CmsSignOpts struct {
-	Hash     string
-	Form     string
-	Detached bool
+	Hash         string // 空の場合は署名用証明書の鍵長から選択します (DefaultDigestForKey)
+	Form         string
+	Detached     bool
+	IncludeChain bool // 署名用CA証明書もcertificatesに格納する
+	SelfVerify   bool // 出力する前に作成した署名を検証する
+
+	// 証明書の鍵用途にnonRepudiationが含まれていなくても署名する
+	SkipKeyUsageCheck bool
+
+	// 署名対象の属性に追加する属性 (CommitmentTypeAttributeなど)
+	SignedAttributes []pkcs7.Attribute
+
+	// 出力ファイルのパーミッション (0の場合は0644)
+	FileMode os.FileMode
+
+	// 署名者の証明書の参照方法
+	// SignerIDSubjectKeyIDはデタッチ署名のみ対応しています
+	SignerIDType SignerIDType
+
+	// trueの場合は署名したときにカードと送受信したAPDUをCmsSignResult.Transcriptに記録します
+	// 記録の形式はJPKISignSigner.EnableTranscriptと同じです
+	Transcript bool
+
+	// 署名用証明書を読み取った後、署名する前に呼び出します
+	// falseを返すと署名鍵を使わずにErrSignCanceledで中断します
+	// 署名する接続でも証明書を読み直し、異なるカードの場合はErrSignCertMismatchで中断します
+	Confirm func(signer *SignCertSubject) (bool, error)
+}
+
+// Confirmが指定されていれば署名者の確認を求めます
+func confirmSigner(confirm func(*SignCertSubject) (bool, error), cert *x509.Certificate) error {
+	if confirm == nil {
+		return nil
+	}
+	ok, err := confirm(NewSignCertSubject(cert))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrSignCanceled
+	}
+	return nil
+}
+
+// 署名用証明書と、IncludeChainの場合は署名用CA証明書を読み取ります
+func getCmsSignCerts(ctx context.Context, pin string, opts CmsSignOpts) (*x509.Certificate, []*x509.Certificate, error) {
+	if !opts.IncludeChain {
+		cert, err := GetJPKISignCertContext(ctx, pin)
+		return cert, nil, err
+	}
+	chain, err := GetJPKICertChainContext(ctx, DefaultCardProfile.SignCertEF,
+		DefaultCardProfile.SignCACertEF, pin)
+	if err != nil {
+		return nil, nil, err
+	}
+	return chain[0], chain[1:], nil
 }
 
 type CmsVerifyOpts struct {
 	Form     string
 	Detached bool
 	Content  string
+	TSARoots *x509.CertPool // タイムスタンプのTSA証明書の信頼点 (タイムスタンプ付きの署名を検証する場合は必須です)
+
+	// 署名者の証明書の信頼点 (nilの場合はカードの署名用CA証明書を使います)
+	Roots *x509.CertPool
+	// 中間CA証明書 (nilの場合は署名に含まれる証明書を使います)
+	Intermediates *x509.CertPool
+
+	// 指定した場合は署名者の証明書のシリアル番号・Subjectが一致しなければ検証を失敗させます
+	// SubjectはCertSummary.Subjectと同じRFC 2253形式で指定します
+	ExpectedSerial  *big.Int
+	ExpectedSubject string
+}
+
+// 署名の監査記録のための情報
+type CmsSignResult struct {
+	Hash            string // ダイジェストアルゴリズム
+	MessageDigest   []byte // 署名したmessageDigest属性の値
+	CertFingerprint string // 署名用証明書のSHA-256フィンガープリント
+	Transcript      []byte // CmsSignOpts.Transcriptを指定した場合のみ、署名時のAPDUの記録
 }
 
 func CmsSignJPKISign(pin string, in string, out string, opts CmsSignOpts) error {
-	digest, err := GetDigestOID(opts.Hash)
+	_, err := CmsSignJPKISignWithResult(pin, in, out, opts)
+	return err
+}
+
+// CmsSignJPKISignと同様ですが、ctxのキャンセルや期限でカードの待機と通信を中断します
+func CmsSignJPKISignContext(ctx context.Context, pin string, in string, out string, opts CmsSignOpts) error {
+	_, err := cmsSignJPKISignWithResult(ctx, pin, in, out, opts)
+	return err
+}
+
+// 署名を行い、署名したダイジェスト値と署名用証明書のフィンガープリントを返します
+func CmsSignJPKISignWithResult(pin string, in string, out string, opts CmsSignOpts) (*CmsSignResult, error) {
+	return cmsSignJPKISignWithResult(context.Background(), pin, in, out, opts)
+}
+
+func cmsSignJPKISignWithResult(ctx context.Context, pin string, in string, out string,
+	opts CmsSignOpts) (*CmsSignResult, error) {
+	if err := checkReadOnly(); err != nil {
+		return nil, err
+	}
+
+	var signed []byte
+	var cert *x509.Certificate
+	var transcript *bytes.Buffer
+	var err error
+	if opts.Transcript {
+		transcript = &bytes.Buffer{}
+	}
+	if opts.Detached {
+		signed, cert, err = cmsSignJPKISignDetached(ctx, pin, in, opts, transcript)
+	} else {
+		signed, cert, err = cmsSignJPKISignAttached(ctx, pin, in, opts, transcript)
+	}
 	if err != nil {
-		return err
+		return nil, contextError(ctx, err)
+	}
+
+	alg, err := resolveDigest(opts.Hash, cert)
+	if err != nil {
+		return nil, err
+	}
+	result, err := newCmsSignResult(signed, cert, alg.name)
+	if err != nil {
+		return nil, err
+	}
+	if transcript != nil {
+		result.Transcript = transcript.Bytes()
+	}
+	if err = writeCms(out, signed, opts.Form, opts.FileMode); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func newCmsSignResult(signed []byte, cert *x509.Certificate, hash string) (*CmsSignResult, error) {
+	p7, err := pkcs7.Parse(signed)
+	if err != nil {
+		return nil, err
+	}
+	result := CmsSignResult{Hash: hash}
+	err = p7.UnmarshalSignedAttribute(pkcs7.OIDAttributeMessageDigest, &result.MessageDigest)
+	if err != nil {
+		return nil, err
+	}
+	result.CertFingerprint = NewCertSummary(cert).SHA256
+	return &result, nil
+}
+
+// transcriptがnilでなければ署名時に送受信したAPDUを記録します
+func cmsSignJPKISignAttached(ctx context.Context, pin string, in string, opts CmsSignOpts,
+	transcript *bytes.Buffer) ([]byte, *x509.Certificate, error) {
+	// pkcs7ライブラリはissuerAndSerialNumberしか作成できない
+	if opts.SignerIDType != SignerIDIssuerAndSerial {
+		return nil, nil, newError("UNSUPPORTED_SID", nil)
+	}
+	// 鍵から選択する場合は証明書を読み取るまで決まらない
+	if opts.Hash != "" {
+		if _, err := lookupDigest(opts.Hash); err != nil {
+			return nil, nil, err
+		}
 	}
 
 	content, err := ioutil.ReadFile(in)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	// 署名用証明書の取得
-	cert, err := GetJPKISignCert(pin)
+	cert, parents, err := getCmsSignCerts(ctx, pin, opts)
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+	if !opts.SkipKeyUsageCheck {
+		err = CheckCertKeyUsage(cert, x509.KeyUsageContentCommitment)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	alg, err := resolveDigest(opts.Hash, cert)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = confirmSigner(opts.Confirm, cert); err != nil {
+		return nil, nil, err
 	}
 
-	privkey := JPKISignSigner{pin, cert.PublicKey}
+	privkey := JPKISignSigner{pin: pin, pubkey: cert.PublicKey, ctx: ctx, cert: cert,
+		transcript: transcript}
 
 	toBeSigned, err := pkcs7.NewSignedData(content)
-	toBeSigned.SetDigestAlgorithm(digest)
-	err = toBeSigned.AddSigner(cert, privkey, pkcs7.SignerInfoConfig{})
+	toBeSigned.SetDigestAlgorithm(alg.oid)
+	err = toBeSigned.AddSigner(cert, privkey, pkcs7.SignerInfoConfig{
+		ExtraSignedAttributes: opts.SignedAttributes,
+	})
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
-
-	if opts.Detached {
-		toBeSigned.Detach()
+	for _, parent := range parents {
+		toBeSigned.AddCertificate(parent)
 	}
 
 	signed, err := toBeSigned.Finish()
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
+	if opts.SelfVerify {
+		if err = selfVerifyCms(signed, cert); err != nil {
+			return nil, nil, err
+		}
+	}
+	return signed, cert, nil
+}
 
-	if err = writeCms(out, signed, opts.Form); err != nil {
-		return err
+// ファイル全体をメモリに読み込まずにデタッチ署名を行います
+// transcriptがnilでなければ署名時に送受信したAPDUを記録します
+func cmsSignJPKISignDetached(ctx context.Context, pin string, in string, opts CmsSignOpts,
+	transcript *bytes.Buffer) ([]byte, *x509.Certificate, error) {
+	if opts.Hash != "" {
+		if _, err := lookupDigest(opts.Hash); err != nil {
+			return nil, nil, err
+		}
 	}
 
-	return nil
-}
+	file, err := os.Open(in)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
 
-func writeCms(out string, signed []byte, form string) error {
-	var file *os.File
-	var err error
-	if out == "" {
-		file = os.Stdout
-	} else {
-		file, err = os.OpenFile(out, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
-		defer file.Close()
+	// 署名用証明書の取得
+	cert, parents, err := getCmsSignCerts(ctx, pin, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !opts.SkipKeyUsageCheck {
+		err = CheckCertKeyUsage(cert, x509.KeyUsageContentCommitment)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 	}
 
-	switch strings.ToUpper(form) {
-	case "PEM":
-		err = pem.Encode(file, &pem.Block{Type: "PKCS7", Bytes: signed})
+	alg, err := resolveDigest(opts.Hash, cert)
+	if err != nil {
+		return nil, nil, err
+	}
+	hash := alg.hash
+	digest, err := streamDigest(file, hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = confirmSigner(opts.Confirm, cert); err != nil {
+		return nil, nil, err
+	}
+
+	privkey := JPKISignSigner{pin: pin, pubkey: cert.PublicKey, ctx: ctx, cert: cert,
+		transcript: transcript}
+	signed, err := buildDetachedCmsWithSignerID(cert, parents, privkey, opts.SignerIDType,
+		hash, alg.oid, digest, opts.SignedAttributes...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.SelfVerify {
+		err = selfVerifyDetachedCms(signed, cert, hash, digest)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
+	}
+	return signed, cert, nil
+}
 
+// permが0の場合は0644で作成します
+func writeCms(out string, signed []byte, form string, perm os.FileMode) error {
+	if out == "" {
+		return encodeCms(os.Stdout, signed, form)
+	}
+	if perm == 0 {
+		perm = 0644
+	}
+	return writeFileAtomic(out, perm, func(w io.Writer) error {
+		return encodeCms(w, signed, form)
+	})
+}
+
+func encodeCms(w io.Writer, signed []byte, form string) error {
+	switch strings.ToUpper(form) {
+	case "PEM":
+		return pem.Encode(w, &pem.Block{Type: "PKCS7", Bytes: signed})
 	case "DER":
-		_, err = file.Write(signed)
-		if err != nil {
-			return err
-		}
+		_, err := w.Write(signed)
+		return err
+	default:
+		return newError("UNSUPPORTED_FORMAT", nil, form)
 	}
-	return nil
 }
 
-func readCMSFile(in string, form string) (*pkcs7.PKCS7, error) {
+func readCMSFile(in string, form string) ([]byte, error) {
 	data, err := ioutil.ReadFile(in)
 	if err != nil {
 		return nil, err
@@ -485,42 +1420,164 @@ func readCMSFile(in string, form string) (*pkcs7.PKCS7, error) {
 	case "DER":
 		signedDer = data
 	default:
-		return nil, fmt.Errorf("サポートされていない形式です: %s", form)
+		return nil, newError("UNSUPPORTED_FORMAT", nil, form)
+	}
+	return signedDer, nil
+}
+
+func CmsVerifyJPKISign(in string, opts CmsVerifyOpts) error {
+	_, err := CmsVerifyJPKISignWithTimestamp(in, opts)
+	return err
+}
+
+// 署名を検証し、タイムスタンプトークンが付与されていればそれも検証します
+// タイムスタンプの時刻(genTime)を返します。トークンが無い場合はnilを返します
+// SignerIDSubjectKeyIDで作成したデタッチ署名も検証できます
+func CmsVerifyJPKISignWithTimestamp(in string, opts CmsVerifyOpts) (*time.Time, error) {
+	signed, err := readCMSFile(in, opts.Form)
+	if err != nil {
+		return nil, err
+	}
+
+	var content []byte
+	if opts.Detached {
+		content, err = ioutil.ReadFile(opts.Content)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if isSubjectKeyIDCms(signed) {
+		if !opts.Detached {
+			return nil, newError("UNSUPPORTED_SID", nil)
+		}
+		roots, err := cmsVerifyRoots(opts)
+		if err != nil {
+			return nil, err
+		}
+		_, genTime, err := verifySubjectKeyIDCms(signed, content, roots, opts)
+		return genTime, err
 	}
 
-	p7, err := pkcs7.Parse(signedDer)
+	p7, err := pkcs7.Parse(signed)
 	if err != nil {
 		return nil, err
 	}
-	return p7, nil
+	if opts.Detached {
+		p7.Content = content
+	}
+	return verifyCmsWithOpts(p7, opts)
 }
 
-func CmsVerifyJPKISign(in string, opts CmsVerifyOpts) error {
+// opts.Rootsがnilの場合はカードの署名用CA証明書を信頼点とします
+func cmsVerifyRoots(opts CmsVerifyOpts) (*x509.CertPool, error) {
+	if opts.Roots != nil {
+		return opts.Roots, nil
+	}
 	cacert, err := GetJPKISignCACert()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	p7, err := readCMSFile(in, opts.Form)
+	roots := x509.NewCertPool()
+	roots.AddCert(cacert)
+	return roots, nil
+}
+
+// 署名を信頼点まで検証し、期待した署名者か、タイムスタンプが正しいかを確認します
+// opts.Rootsがnilの場合はカードの署名用CA証明書を信頼点とします
+func verifyCmsWithOpts(p7 *pkcs7.PKCS7, opts CmsVerifyOpts) (*time.Time, error) {
+	roots, err := cmsVerifyRoots(opts)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if opts.Detached {
-		content, err := ioutil.ReadFile(opts.Content)
+	if opts.Intermediates == nil {
+		err = p7.VerifyWithChain(roots)
+	} else {
+		err = verifyCmsWithIntermediates(p7, roots, opts.Intermediates)
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = checkExpectedSigner(p7.GetOnlySigner(), opts.ExpectedSerial, opts.ExpectedSubject)
+	if err != nil {
+		return nil, err
+	}
+
+	return verifyCmsTimestamp(p7, opts.TSARoots)
+}
+
+// 指定した種別のPINの残り試行回数を取得します
+//
+//	CARD_INPUT_HELPER 券面入力補助用PIN (EF 00 11)
+//	JPKI_AUTH         JPKI利用者証明用PIN (EF 00 18)
+//	JPKI_SIGN         JPKI署名用パスワード (EF 00 1B)
+func GetPinRetryCount(pintype string) (int, error) {
+	reader, err := NewReader(OptionDebug)
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Finalize()
+	err = reader.Connect()
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	switch pintype {
+	case "CARD_INPUT_HELPER":
+		textAP, err := reader.SelectTextAP()
 		if err != nil {
-			return err
+			return 0, err
 		}
-		p7.Content = content
+		count, err = textAP.LookupPin()
+		if err != nil {
+			return 0, err
+		}
+	case "JPKI_AUTH":
+		jpkiAP, err := reader.SelectJPKIAP()
+		if err != nil {
+			return 0, err
+		}
+		count, err = jpkiAP.LookupAuthPin()
+		if err != nil {
+			return 0, err
+		}
+	case "JPKI_SIGN":
+		jpkiAP, err := reader.SelectJPKIAP()
+		if err != nil {
+			return 0, err
+		}
+		count, err = jpkiAP.LookupSignPin()
+		if err != nil {
+			return 0, err
+		}
+	default:
+		return 0, newError("UNKNOWN_PIN_TYPE", nil, pintype)
+	}
+	if count < 0 {
+		return 0, newError("PIN_RETRY_UNKNOWN", nil)
 	}
+	return count, nil
+}
 
-	certPool := x509.NewCertPool()
-	certPool.AddCert(cacert)
-	err = p7.VerifyWithChain(certPool)
+// 照合に失敗してもロックされない(残り試行回数が2回以上)かを確認し、残り試行回数と共に返します
+// 照合は行わないため試行回数は減りません。pintypeはGetPinRetryCountと同じです
+func CanVerifyWithoutRisk(pintype string) (bool, int, error) {
+	count, err := GetPinRetryCount(pintype)
 	if err != nil {
-		return err
+		return false, 0, err
 	}
+	return isSafeRetryCount(count), count, nil
+}
 
-	return nil
+// 残り1回で照合に失敗するとロックされるため、2回以上残っている場合のみ安全とします
+func isSafeRetryCount(count int) bool {
+	return count > 1
+}
+
+// JPKI利用者証明用PINの残り試行回数を取得します
+func GetAuthPinRetryCount() (int, error) {
+	return GetPinRetryCount("JPKI_AUTH")
 }
 
 func GetPinStatus() (map[string]int, error) {
@@ -533,7 +1590,10 @@ func GetPinStatus() (map[string]int, error) {
 	if err != nil {
 		return nil, err
 	}
+	return readPinStatus(reader)
+}
 
+func readPinStatus(reader *Reader) (map[string]int, error) {
 	status := map[string]int{}
 
 	visualAP, err := reader.SelectVisualAP()
@@ -0,0 +1,119 @@
+// Login Assertion
+
+package libmyna
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
+// ログイン用アサーションのヘッダ
+// x5cには署名に使った利用者証明用証明書のDERを格納します
+type loginAssertionHeader struct {
+	Alg string   `json:"alg"`
+	Typ string   `json:"typ"`
+	X5c [][]byte `json:"x5c"`
+}
+
+type loginAssertionPayload struct {
+	Nonce string `json:"nonce"` // base64url
+	Iat   int64  `json:"iat"`
+}
+
+// 検証済みのログイン用アサーション
+type LoginAssertion struct {
+	Nonce       []byte
+	IssuedAt    time.Time
+	Certificate *x509.Certificate // 利用者証明用証明書 (信頼点までの検証は呼び出し元で行います)
+}
+
+var b64url = base64.RawURLEncoding
+
+// 利用者証明用証明書とnonce・発行時刻からアサーションを組み立てます
+// signはSHA-256のDigestInfoに署名する関数です
+func buildLoginAssertion(cert *x509.Certificate, nonce []byte, now time.Time,
+	sign func(digestInfo []byte) ([]byte, error)) ([]byte, error) {
+
+	header, err := json.Marshal(loginAssertionHeader{
+		Alg: "RS256", Typ: "JWT", X5c: [][]byte{cert.Raw}})
+	if err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(loginAssertionPayload{
+		Nonce: b64url.EncodeToString(nonce), Iat: now.Unix()})
+	if err != nil {
+		return nil, err
+	}
+	input := b64url.EncodeToString(header) + "." + b64url.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(input))
+	signature, err := sign(makeDigestInfo(crypto.SHA256, digest[:]))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(input + "." + b64url.EncodeToString(signature)), nil
+}
+
+// SignLoginAssertionで作成したアサーションを解析し、署名を同梱の証明書で検証します
+// nonceがアサーションの値と一致しない場合はエラーを返します
+// 証明書がJPKIの信頼点まで検証できるかは、VerifyCertificateFullなどで別途確認してください
+func ParseLoginAssertion(token []byte, nonce []byte) (*LoginAssertion, error) {
+	parts := bytes.Split(token, []byte("."))
+	if len(parts) != 3 {
+		return nil, newError("INVALID_ASSERTION", nil)
+	}
+	var header loginAssertionHeader
+	if err := decodeAssertionPart(parts[0], &header); err != nil {
+		return nil, err
+	}
+	if header.Alg != "RS256" || len(header.X5c) == 0 {
+		return nil, newError("INVALID_ASSERTION", nil)
+	}
+	cert, err := x509.ParseCertificate(header.X5c[0])
+	if err != nil {
+		return nil, newError("INVALID_ASSERTION", err)
+	}
+	signature, err := b64url.DecodeString(string(parts[2]))
+	if err != nil {
+		return nil, newError("INVALID_ASSERTION", err)
+	}
+	input := token[:len(parts[0])+1+len(parts[1])]
+	digest := sha256.Sum256(input)
+	err = VerifyRawSignature(cert, crypto.SHA256, digest[:], signature)
+	if err != nil {
+		return nil, newError("INVALID_ASSERTION", err)
+	}
+
+	var payload loginAssertionPayload
+	if err = decodeAssertionPart(parts[1], &payload); err != nil {
+		return nil, err
+	}
+	signedNonce, err := b64url.DecodeString(payload.Nonce)
+	if err != nil {
+		return nil, newError("INVALID_ASSERTION", err)
+	}
+	if !bytes.Equal(signedNonce, nonce) {
+		return nil, newError("NONCE_MISMATCH", nil)
+	}
+	assertion := LoginAssertion{
+		Nonce:       signedNonce,
+		IssuedAt:    time.Unix(payload.Iat, 0),
+		Certificate: cert,
+	}
+	return &assertion, nil
+}
+
+func decodeAssertionPart(part []byte, v interface{}) error {
+	data, err := b64url.DecodeString(string(part))
+	if err != nil {
+		return newError("INVALID_ASSERTION", err)
+	}
+	if err = json.Unmarshal(data, v); err != nil {
+		return newError("INVALID_ASSERTION", err)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package libmyna
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+)
+
+func TestLoginAssertion(t *testing.T) {
+	cert, key := newTestCert(t)
+	nonce := []byte("server nonce")
+	now := time.Unix(1700000000, 0)
+	sign := func(digestInfo []byte) ([]byte, error) {
+		// カードと同様にDigestInfoをそのままPKCS#1 v1.5で署名する
+		return rsa.SignPKCS1v15(rand.Reader, key, crypto.Hash(0), digestInfo)
+	}
+	token, err := buildLoginAssertion(cert, nonce, now, sign)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertion, err := ParseLoginAssertion(token, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(assertion.Nonce, nonce) || !assertion.IssuedAt.Equal(now) ||
+		!assertion.Certificate.Equal(cert) {
+		t.Errorf("unexpected assertion: %+v", assertion)
+	}
+
+	if _, err = ParseLoginAssertion(token, []byte("other")); err == nil {
+		t.Error("ParseLoginAssertion should fail for another nonce")
+	}
+	tampered := append([]byte{}, token...)
+	tampered[len(tampered)-2] ^= 0x01
+	if _, err = ParseLoginAssertion(tampered, nonce); err == nil {
+		t.Error("ParseLoginAssertion should fail for a tampered signature")
+	}
+}
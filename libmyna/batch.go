@@ -0,0 +1,91 @@
+// Batch Signing
+
+package libmyna
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const DefaultOutputPattern = "{base}.p7s"
+
+type CmsSignBatchOpts struct {
+	CmsSignOpts
+	OutputDir     string // 空の場合は入力ファイルと同じディレクトリ
+	OutputPattern string // 空の場合はDefaultOutputPattern
+}
+
+// 入力ファイル名から出力ファイルのパスを求めます
+//
+//	{base} 入力ファイル名 (foo.pdf)
+//	{name} 拡張子を除いた入力ファイル名 (foo)
+//	{ext}  入力ファイルの拡張子 (.pdf)
+func ResolveOutputPath(in string, dir string, pattern string) string {
+	if pattern == "" {
+		pattern = DefaultOutputPattern
+	}
+	if dir == "" {
+		dir = filepath.Dir(in)
+	}
+	base := filepath.Base(in)
+	ext := filepath.Ext(base)
+	name := strings.NewReplacer(
+		"{base}", base,
+		"{name}", strings.TrimSuffix(base, ext),
+		"{ext}", ext,
+	).Replace(pattern)
+	return filepath.Join(dir, name)
+}
+
+// 署名の形式に応じた出力ファイルの拡張子を返します
+// PEMは.pem、DERはデタッチ署名なら.p7s、コンテンツを含む署名なら.p7mです
+func CmsOutputExt(opts CmsSignOpts) string {
+	switch {
+	case strings.EqualFold(opts.Form, "pem"):
+		return ".pem"
+	case opts.Detached:
+		return ".p7s"
+	default:
+		return ".p7m"
+	}
+}
+
+// outが空または既存のディレクトリの場合に、入力ファイル名にextを付けた出力先を返します
+// それ以外の場合はoutをそのまま返します
+func ResolveDefaultOutput(in string, out string, ext string) string {
+	if out == "" {
+		return ResolveOutputPath(in, "", "{base}"+ext)
+	}
+	if info, err := os.Stat(out); err == nil && info.IsDir() {
+		return ResolveOutputPath(in, out, "{base}"+ext)
+	}
+	return out
+}
+
+// 複数のファイルに署名し、出力したファイルのパスを返します
+// 出力先が既に存在する場合や入力ファイル同士で出力先が重なる場合は
+// 署名を始める前にエラーを返します
+func CmsSignJPKISignBatch(pin string, inputs []string, opts CmsSignBatchOpts) ([]string, error) {
+	outputs := make([]string, len(inputs))
+	seen := map[string]string{}
+	for i, in := range inputs {
+		out := ResolveOutputPath(in, opts.OutputDir, opts.OutputPattern)
+		if prev, ok := seen[out]; ok {
+			return nil, newError("OUTPUT_COLLISION", nil, prev, in, out)
+		}
+		if _, err := os.Stat(out); err == nil {
+			return nil, newError("OUTPUT_EXISTS", nil, out)
+		}
+		seen[out] = in
+		outputs[i] = out
+	}
+
+	for i, in := range inputs {
+		err := CmsSignJPKISign(pin, in, outputs[i], opts.CmsSignOpts)
+		if err != nil {
+			return outputs[:i], err
+		}
+	}
+	return outputs, nil
+}
@@ -0,0 +1,73 @@
+package libmyna
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveOutputPath(t *testing.T) {
+	tests := []struct {
+		in, dir, pattern, want string
+	}{
+		{"docs/foo.pdf", "", "", "docs/foo.pdf.p7s"},
+		{"docs/foo.pdf", "out", "{name}.p7m", "out/foo.p7m"},
+		{"foo.pdf", "out", "{name}-signed{ext}.p7s", "out/foo-signed.pdf.p7s"},
+	}
+	for _, test := range tests {
+		got := ResolveOutputPath(test.in, test.dir, test.pattern)
+		if got != filepath.FromSlash(test.want) {
+			t.Errorf("ResolveOutputPath(%q, %q, %q) = %q, want %q",
+				test.in, test.dir, test.pattern, got, test.want)
+		}
+	}
+}
+
+func TestCmsSignJPKISignBatchCollision(t *testing.T) {
+	dir, err := ioutil.TempDir("", "myna")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	opts := CmsSignBatchOpts{OutputDir: dir, OutputPattern: "{name}.p7s"}
+	_, err = CmsSignJPKISignBatch("", []string{"a/foo.pdf", "b/foo.txt"}, opts)
+	if err == nil {
+		t.Error("batch signing should fail for colliding outputs")
+	}
+
+	existing := filepath.Join(dir, "bar.p7s")
+	if err = ioutil.WriteFile(existing, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = CmsSignJPKISignBatch("", []string{"bar.pdf"}, opts)
+	if err == nil || err.Error() != newError("OUTPUT_EXISTS", nil, existing).Error() {
+		t.Errorf("batch signing should fail for an existing output: %v", err)
+	}
+}
+
+func TestResolveDefaultOutput(t *testing.T) {
+	dir, err := ioutil.TempDir("", "myna")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		opts CmsSignOpts
+		out  string
+		want string
+	}{
+		{CmsSignOpts{Form: "der"}, "", "docs/foo.pdf.p7m"},
+		{CmsSignOpts{Form: "der", Detached: true}, "", "docs/foo.pdf.p7s"},
+		{CmsSignOpts{Form: "PEM", Detached: true}, dir, filepath.Join(dir, "foo.pdf.pem")},
+		{CmsSignOpts{Form: "der"}, "signed.bin", "signed.bin"},
+	}
+	for _, test := range tests {
+		got := ResolveDefaultOutput("docs/foo.pdf", test.out, CmsOutputExt(test.opts))
+		if got != filepath.FromSlash(test.want) {
+			t.Errorf("ResolveDefaultOutput(%+v, %q) = %q, want %q", test.opts, test.out, got, test.want)
+		}
+	}
+}
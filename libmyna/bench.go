@@ -0,0 +1,75 @@
+// Signing Benchmark
+
+package libmyna
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"sort"
+	"time"
+)
+
+// 署名1回あたりの所要時間の統計
+type BenchStats struct {
+	Count int
+	Min   time.Duration
+	Avg   time.Duration
+	Max   time.Duration
+	P95   time.Duration
+}
+
+func newBenchStats(durations []time.Duration) *BenchStats {
+	stats := BenchStats{Count: len(durations)}
+	if len(durations) == 0 {
+		return &stats
+	}
+	sorted := append([]time.Duration{}, durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	stats.Min = sorted[0]
+	stats.Max = sorted[len(sorted)-1]
+	stats.Avg = total / time.Duration(len(sorted))
+	// nearest-rank法
+	rank := (len(sorted)*95 + 99) / 100
+	stats.P95 = sorted[rank-1]
+	return &stats
+}
+
+// 署名用パスワードを1回照合し、payloadのSHA-256ダイジェスト値にn回署名して所要時間を計測します
+// 接続とPINの照合の時間は含めず、カードの署名処理の時間のみを計測します
+func (self *Session) BenchmarkSign(pin string, payload []byte, n int) (*BenchStats, error) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	if err := self.ensureCard(); err != nil {
+		return nil, err
+	}
+	err := self.reader.AuthenticateAP("JPKI", self.reader.profile.SignPinEF, pin)
+	if err != nil {
+		return nil, err
+	}
+	jpkiAP := JPKIAP{self.reader}
+	digest := sha256.Sum256(payload)
+	digestInfo := makeDigestInfo(crypto.SHA256, digest[:])
+	durations := make([]time.Duration, 0, n)
+	for i := 0; i < n; i++ {
+		start := time.Now()
+		_, err = jpkiAP.SignWithSignKey(digestInfo)
+		if err != nil {
+			return nil, err
+		}
+		durations = append(durations, time.Since(start))
+	}
+	return newBenchStats(durations), nil
+}
+
+func BenchmarkSign(pin string, payload []byte, n int) (*BenchStats, error) {
+	session, err := NewSession(OptionDebug)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+	return session.BenchmarkSign(pin, payload, n)
+}
@@ -0,0 +1,23 @@
+package libmyna
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewBenchStats(t *testing.T) {
+	var durations []time.Duration
+	for i := 20; i >= 1; i-- {
+		durations = append(durations, time.Duration(i)*time.Millisecond)
+	}
+	stats := newBenchStats(durations)
+	if stats.Count != 20 || stats.Min != time.Millisecond || stats.Max != 20*time.Millisecond {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if stats.Avg != 10500*time.Microsecond || stats.P95 != 19*time.Millisecond {
+		t.Errorf("unexpected avg/p95: %+v", stats)
+	}
+	if empty := newBenchStats(nil); empty.Count != 0 {
+		t.Errorf("unexpected empty stats: %+v", empty)
+	}
+}
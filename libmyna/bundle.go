@@ -0,0 +1,168 @@
+// Signature Bundle
+
+package libmyna
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/yu-ichiro/pkcs7"
+)
+
+// 署名アーカイブ(zip)に格納するファイルの名前
+const (
+	bundleContentName   = "content"
+	bundleSignatureName = "content.p7s"
+	bundleManifestName  = "manifest.json"
+)
+
+// 署名アーカイブのmanifest.json
+type BundleManifest struct {
+	File         string    `json:"file"`          // 署名したファイルの元の名前
+	Hash         string    `json:"hash"`          // ダイジェストアルゴリズム
+	SignerSerial string    `json:"signer_serial"` // 署名用証明書のシリアル番号(16進数)
+	SignedAt     time.Time `json:"signed_at"`
+}
+
+// inのデタッチ署名を作成し、元のファイル・署名(DER)・manifest.jsonを1つのzipに格納します
+// opts.DetachedとFormは無視します
+func CmsSignBundle(pin string, in string, outZip string, opts CmsSignOpts) error {
+	signed, cert, err := cmsSignJPKISignDetached(context.Background(), pin, in, opts, nil)
+	if err != nil {
+		return err
+	}
+	alg, err := resolveDigest(opts.Hash, cert)
+	if err != nil {
+		return err
+	}
+	manifest := BundleManifest{
+		File:         filepath.Base(in),
+		Hash:         alg.name,
+		SignerSerial: fmt.Sprintf("%X", cert.SerialNumber),
+		SignedAt:     time.Now().UTC(),
+	}
+	perm := opts.FileMode
+	if perm == 0 {
+		perm = 0644
+	}
+	return writeFileAtomic(outZip, perm, func(w io.Writer) error {
+		return writeBundle(w, in, signed, &manifest)
+	})
+}
+
+func writeBundle(w io.Writer, in string, signed []byte, manifest *BundleManifest) error {
+	file, err := os.Open(in)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	zw := zip.NewWriter(w)
+	entry, err := zw.Create(bundleContentName)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(entry, file); err != nil {
+		return err
+	}
+	entry, err = zw.Create(bundleSignatureName)
+	if err != nil {
+		return err
+	}
+	if _, err = entry.Write(signed); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	entry, err = zw.Create(bundleManifestName)
+	if err != nil {
+		return err
+	}
+	if _, err = entry.Write(data); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// CmsSignBundleで作成したzipの署名を格納されたファイルに対して検証します
+// 信頼点などはCmsVerifyJPKISignと同じくoptsで指定します(Form, Detached, Contentは無視します)
+// manifest.jsonのシリアル番号が署名者と異なる場合もエラーを返します
+func VerifyBundle(in string, opts CmsVerifyOpts) (*BundleManifest, error) {
+	zr, err := zip.OpenReader(in)
+	if err != nil {
+		return nil, newError("INVALID_BUNDLE", err, in)
+	}
+	defer zr.Close()
+	entries := map[string][]byte{}
+	for _, f := range zr.File {
+		switch f.Name {
+		case bundleContentName, bundleSignatureName, bundleManifestName:
+		default:
+			continue
+		}
+		data, err := readZipEntry(f)
+		if err != nil {
+			return nil, newError("INVALID_BUNDLE", err, f.Name)
+		}
+		entries[f.Name] = data
+	}
+	for _, name := range []string{bundleContentName, bundleSignatureName, bundleManifestName} {
+		if _, ok := entries[name]; !ok {
+			return nil, newError("INVALID_BUNDLE", nil, name)
+		}
+	}
+
+	var manifest BundleManifest
+	err = json.Unmarshal(entries[bundleManifestName], &manifest)
+	if err != nil {
+		return nil, newError("INVALID_BUNDLE", err, bundleManifestName)
+	}
+	signer, err := verifyBundleSignature(entries[bundleSignatureName], entries[bundleContentName], opts)
+	if err != nil {
+		return nil, err
+	}
+	if signer == nil || fmt.Sprintf("%X", signer.SerialNumber) != manifest.SignerSerial {
+		return nil, newError("INVALID_BUNDLE", nil, bundleManifestName)
+	}
+	return &manifest, nil
+}
+
+// バンドルのデタッチ署名を検証し、署名者の証明書を返します
+func verifyBundleSignature(signed []byte, content []byte, opts CmsVerifyOpts) (*x509.Certificate, error) {
+	if isSubjectKeyIDCms(signed) {
+		roots, err := cmsVerifyRoots(opts)
+		if err != nil {
+			return nil, err
+		}
+		signer, _, err := verifySubjectKeyIDCms(signed, content, roots, opts)
+		return signer, err
+	}
+	p7, err := pkcs7.Parse(signed)
+	if err != nil {
+		return nil, newError("INVALID_BUNDLE", err, bundleSignatureName)
+	}
+	p7.Content = content
+	if _, err = verifyCmsWithOpts(p7, opts); err != nil {
+		return nil, err
+	}
+	return p7.GetOnlySigner(), nil
+}
+
+func readZipEntry(f *zip.File) ([]byte, error) {
+	r, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
@@ -0,0 +1,74 @@
+package libmyna
+
+import (
+	"crypto"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yu-ichiro/pkcs7"
+)
+
+func TestVerifyBundle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "myna")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cert, key := newTestCert(t)
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+
+	writeTestBundle := func(name string, content string, signedContent string) string {
+		in := filepath.Join(dir, name+".txt")
+		if err := ioutil.WriteFile(in, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		h := crypto.SHA256.New()
+		h.Write([]byte(signedContent))
+		signed, err := buildDetachedCms(cert, nil, key, crypto.SHA256,
+			pkcs7.OIDDigestAlgorithmSHA256, h.Sum(nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		manifest := BundleManifest{
+			File:         filepath.Base(in),
+			Hash:         "sha256",
+			SignerSerial: fmt.Sprintf("%X", cert.SerialNumber),
+			SignedAt:     time.Now().UTC(),
+		}
+		out := filepath.Join(dir, name+".zip")
+		err = writeFileAtomic(out, 0644, func(w io.Writer) error {
+			return writeBundle(w, in, signed, &manifest)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	manifest, err := VerifyBundle(writeTestBundle("ok", "hello myna", "hello myna"),
+		CmsVerifyOpts{Roots: roots})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.File != "ok.txt" || manifest.Hash != "sha256" {
+		t.Errorf("unexpected manifest: %+v", manifest)
+	}
+
+	_, err = VerifyBundle(writeTestBundle("tampered", "hello myna", "tampered"),
+		CmsVerifyOpts{Roots: roots})
+	if err == nil {
+		t.Error("VerifyBundle should fail for tampered content")
+	}
+
+	notZip := filepath.Join(dir, "ok.txt")
+	if _, err = VerifyBundle(notZip, CmsVerifyOpts{Roots: roots}); err == nil {
+		t.Error("VerifyBundle should fail for a non-zip file")
+	}
+}
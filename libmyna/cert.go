@@ -0,0 +1,65 @@
+// Certificate Summary
+
+package libmyna
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type CertSummary struct {
+	Subject      string `json:"subject"`
+	Issuer       string `json:"issuer"`
+	Serial       string `json:"serial"`
+	NotBefore    string `json:"notBefore"`
+	NotAfter     string `json:"notAfter"`
+	NotBeforeJP  string `json:"notBeforeJapanese"` // 和暦 (日本時間)
+	NotAfterJP   string `json:"notAfterJapanese"`
+	KeyAlgorithm string `json:"keyAlgorithm"`
+	KeySize      int    `json:"keySize"`
+	SHA256       string `json:"sha256"`
+}
+
+func NewCertSummary(cert *x509.Certificate) *CertSummary {
+	fingerprint := sha256.Sum256(cert.Raw)
+	summary := CertSummary{
+		Subject:      cert.Subject.String(),
+		Issuer:       cert.Issuer.String(),
+		Serial:       fmt.Sprintf("%X", cert.SerialNumber),
+		NotBefore:    cert.NotBefore.UTC().Format(time.RFC3339),
+		NotAfter:     cert.NotAfter.UTC().Format(time.RFC3339),
+		NotBeforeJP:  FormatJapaneseEra(cert.NotBefore),
+		NotAfterJP:   FormatJapaneseEra(cert.NotAfter),
+		KeyAlgorithm: cert.PublicKeyAlgorithm.String(),
+		SHA256:       hex.EncodeToString(fingerprint[:]),
+	}
+	switch pubkey := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		summary.KeySize = pubkey.N.BitLen()
+	case *ecdsa.PublicKey:
+		summary.KeySize = pubkey.Curve.Params().BitSize
+	}
+	return &summary
+}
+
+// 証明書の主要な項目をJSONで返します
+func CertSummaryJSON(cert *x509.Certificate) ([]byte, error) {
+	return json.Marshal(NewCertSummary(cert))
+}
+
+// 署名用証明書のSubjectとIssuerのDERからSHA-256の16進数の識別子を作成します
+// 同じ発行者から同じ内容で発行された証明書では同じ値になるため、マイナンバーを扱わずに
+// 署名者が同一かを判定できます。ただし氏名・住所などが変わって再発行されると値も変わります
+// Subjectを推測できる相手には照合されうるため、公開する場合は別途鍵付きハッシュにしてください
+func SignerPseudonym(cert *x509.Certificate) string {
+	h := sha256.New()
+	h.Write(cert.RawSubject)
+	h.Write(cert.RawIssuer)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,81 @@
+package libmyna
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yu-ichiro/pkcs7"
+)
+
+func TestCertSummaryJSON(t *testing.T) {
+	cert, _ := newTestCert(t)
+	data, err := CertSummaryJSON(cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var summary CertSummary
+	if err = json.Unmarshal(data, &summary); err != nil {
+		t.Fatal(err)
+	}
+	if summary.Subject != "CN=test" || summary.Serial != "1" {
+		t.Errorf("unexpected summary: %s", data)
+	}
+	if summary.KeyAlgorithm != "RSA" || summary.KeySize != 2048 {
+		t.Errorf("unexpected key: %s %d", summary.KeyAlgorithm, summary.KeySize)
+	}
+	if len(summary.SHA256) != 64 {
+		t.Errorf("unexpected fingerprint: %s", summary.SHA256)
+	}
+}
+
+func TestExportCertChainP7B(t *testing.T) {
+	cert, _ := newTestCert(t)
+	ca, _ := newTestCert(t)
+	dir, err := ioutil.TempDir("", "myna")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	out := filepath.Join(dir, "chain.p7b")
+	err = ExportCertChainP7B([]*x509.Certificate{cert, ca}, out, "der")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p7, err := pkcs7.Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p7.Certificates) != 2 || len(p7.Signers) != 0 {
+		t.Errorf("unexpected p7b: %d certs, %d signers", len(p7.Certificates), len(p7.Signers))
+	}
+	if err = ExportCertChainP7B(nil, out, "der"); err == nil {
+		t.Error("ExportCertChainP7B should fail for an empty chain")
+	}
+}
+
+func TestSignerPseudonym(t *testing.T) {
+	cert, _ := newTestCert(t)
+	renewed, _ := newTestCert(t)
+	pseudonym := SignerPseudonym(cert)
+	if len(pseudonym) != 64 {
+		t.Errorf("SignerPseudonym = %q", pseudonym)
+	}
+	// 鍵やシリアル番号が変わってもSubjectとIssuerが同じなら同じ値
+	if SignerPseudonym(renewed) != pseudonym {
+		t.Error("SignerPseudonym should be stable for the same subject and issuer")
+	}
+	other := *cert
+	other.RawSubject = append([]byte{}, cert.RawIssuer...)
+	other.RawIssuer = []byte{0x30, 0x00}
+	if SignerPseudonym(&other) == pseudonym {
+		t.Error("SignerPseudonym should differ for another issuer")
+	}
+}
@@ -0,0 +1,471 @@
+// CMS SignedData Builder
+
+package libmyna
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/yu-ichiro/pkcs7"
+)
+
+// CAdESのcommitment-type-indication属性と、その値に指定するOID
+var (
+	OIDAttributeCommitmentType   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 16}
+	OIDCommitmentProofOfOrigin   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 6, 1}
+	OIDCommitmentProofOfApproval = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 6, 5}
+	OIDCommitmentProofOfCreation = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 6, 6}
+)
+
+// SignerInfoで署名者の証明書を参照する方法
+type SignerIDType int
+
+const (
+	SignerIDIssuerAndSerial SignerIDType = iota // issuerAndSerialNumber (SignerInfo v1)
+	SignerIDSubjectKeyID                        // subjectKeyIdentifier (SignerInfo v3)
+)
+
+type cmsCommitmentTypeIndication struct {
+	CommitmentTypeID asn1.ObjectIdentifier
+}
+
+// CmsSignOpts.SignedAttributesに指定するcommitment-type-indication属性を作成します
+func CommitmentTypeAttribute(commitmentType asn1.ObjectIdentifier) pkcs7.Attribute {
+	return pkcs7.Attribute{
+		Type:  OIDAttributeCommitmentType,
+		Value: cmsCommitmentTypeIndication{CommitmentTypeID: commitmentType},
+	}
+}
+
+type cmsAttribute struct {
+	Type  asn1.ObjectIdentifier
+	Value asn1.RawValue
+}
+
+type cmsIssuerAndSerial struct {
+	IssuerName   asn1.RawValue
+	SerialNumber *big.Int
+}
+
+type cmsSignerInfo struct {
+	Version                   int
+	SignerIdentifier          asn1.RawValue // issuerAndSerialNumber または [0] subjectKeyIdentifier
+	DigestAlgorithm           pkix.AlgorithmIdentifier
+	AuthenticatedAttributes   asn1.RawValue
+	DigestEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedDigest           []byte
+	UnauthenticatedAttributes asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+type cmsSignedData struct {
+	Version                    int
+	DigestAlgorithmIdentifiers []pkix.AlgorithmIdentifier `asn1:"set"`
+	ContentInfo                ContentInfo
+	Certificates               asn1.RawValue
+	SignerInfos                []cmsSignerInfo `asn1:"set"`
+}
+
+func newCmsAttribute(oid asn1.ObjectIdentifier, value interface{}) (*cmsAttribute, error) {
+	der, err := asn1.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	attr := cmsAttribute{
+		Type:  oid,
+		Value: asn1.RawValue{Tag: asn1.TagSet, IsCompound: true, Bytes: der},
+	}
+	return &attr, nil
+}
+
+// 属性をDERのSET OF順に並べて連結します
+func marshalCmsAttributes(attrs []*cmsAttribute) ([]byte, error) {
+	var encoded [][]byte
+	for _, attr := range attrs {
+		der, err := asn1.Marshal(*attr)
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, der)
+	}
+	sort.Slice(encoded, func(i, j int) bool {
+		return bytes.Compare(encoded[i], encoded[j]) < 0
+	})
+	return bytes.Join(encoded, nil), nil
+}
+
+// SignerInfoのsidとバージョンを作成します
+func newCmsSignerIdentifier(cert *x509.Certificate, sidType SignerIDType) (asn1.RawValue, int, error) {
+	switch sidType {
+	case SignerIDIssuerAndSerial:
+		der, err := asn1.Marshal(cmsIssuerAndSerial{
+			IssuerName:   asn1.RawValue{FullBytes: cert.RawIssuer},
+			SerialNumber: cert.SerialNumber,
+		})
+		return asn1.RawValue{FullBytes: der}, 1, err
+	case SignerIDSubjectKeyID:
+		if len(cert.SubjectKeyId) == 0 {
+			return asn1.RawValue{}, 0, newError("NO_SUBJECT_KEY_ID", nil)
+		}
+		sid := asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0,
+			Bytes: cert.SubjectKeyId}
+		return sid, 3, nil
+	default:
+		return asn1.RawValue{}, 0, newError("UNSUPPORTED_SID", nil)
+	}
+}
+
+// コンテンツのダイジェスト値からデタッチ署名を作成します
+// parentsに指定した証明書は署名者の証明書と共にcertificatesに格納します
+// extraに指定した属性は署名対象の属性に追加します
+func buildDetachedCms(cert *x509.Certificate, parents []*x509.Certificate, signer crypto.Signer,
+	hash crypto.Hash, digestOID asn1.ObjectIdentifier,
+	digest []byte, extra ...pkcs7.Attribute) ([]byte, error) {
+
+	return buildDetachedCmsWithSignerID(cert, parents, signer, SignerIDIssuerAndSerial,
+		hash, digestOID, digest, extra...)
+}
+
+// buildDetachedCmsと同じですが、sidTypeで署名者の証明書の参照方法を指定します
+// subjectKeyIdentifierの場合はSignedDataとSignerInfoのバージョンを3にします
+func buildDetachedCmsWithSignerID(cert *x509.Certificate, parents []*x509.Certificate,
+	signer crypto.Signer, sidType SignerIDType, hash crypto.Hash,
+	digestOID asn1.ObjectIdentifier, digest []byte, extra ...pkcs7.Attribute) ([]byte, error) {
+
+	sid, version, err := newCmsSignerIdentifier(cert, sidType)
+	if err != nil {
+		return nil, err
+	}
+	attrsDer, err := buildCmsSignedAttrs(digest, extra...)
+	if err != nil {
+		return nil, err
+	}
+
+	// 署名対象は SET OF としてエンコードした属性
+	toBeSigned, err := asn1.Marshal(asn1.RawValue{
+		Tag: asn1.TagSet, IsCompound: true, Bytes: attrsDer})
+	if err != nil {
+		return nil, err
+	}
+	h := hash.New()
+	h.Write(toBeSigned)
+	signature, err := signer.Sign(rand.Reader, h.Sum(nil), hash)
+	if err != nil {
+		return nil, err
+	}
+
+	signerInfo := cmsSignerInfo{
+		Version:          version,
+		SignerIdentifier: sid,
+		DigestAlgorithm:  pkix.AlgorithmIdentifier{Algorithm: digestOID},
+		AuthenticatedAttributes: asn1.RawValue{
+			Class: asn1.ClassContextSpecific, Tag: 0,
+			IsCompound: true, Bytes: attrsDer},
+		DigestEncryptionAlgorithm: pkix.AlgorithmIdentifier{
+			Algorithm: pkcs7.OIDEncryptionAlgorithmRSA},
+		EncryptedDigest: signature,
+	}
+
+	certs := cert.Raw
+	for _, parent := range parents {
+		certs = append(certs[:len(certs):len(certs)], parent.Raw...)
+	}
+	sd := cmsSignedData{
+		Version: version,
+		DigestAlgorithmIdentifiers: []pkix.AlgorithmIdentifier{
+			{Algorithm: digestOID}},
+		ContentInfo: ContentInfo{ContentType: pkcs7.OIDData},
+		Certificates: asn1.RawValue{
+			Class: asn1.ClassContextSpecific, Tag: 0,
+			IsCompound: true, Bytes: certs},
+		SignerInfos: []cmsSignerInfo{signerInfo},
+	}
+	inner, err := asn1.Marshal(sd)
+	if err != nil {
+		return nil, err
+	}
+
+	outer := ContentInfo{
+		ContentType: pkcs7.OIDSignedData,
+		Content: asn1.RawValue{
+			Class: asn1.ClassContextSpecific, Tag: 0,
+			IsCompound: true, Bytes: inner},
+	}
+	return asn1.Marshal(outer)
+}
+
+// 署名対象の属性(contentType, messageDigest, signingTime, extra)を作成し、
+// DERのSET OF順に並べて連結します
+func buildCmsSignedAttrs(digest []byte, extra ...pkcs7.Attribute) ([]byte, error) {
+	var attrs []*cmsAttribute
+	attr, err := newCmsAttribute(pkcs7.OIDAttributeContentType, pkcs7.OIDData)
+	if err != nil {
+		return nil, err
+	}
+	attrs = append(attrs, attr)
+	attr, err = newCmsAttribute(pkcs7.OIDAttributeMessageDigest, digest)
+	if err != nil {
+		return nil, err
+	}
+	attrs = append(attrs, attr)
+	attr, err = newCmsAttribute(pkcs7.OIDAttributeSigningTime, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	attrs = append(attrs, attr)
+	for _, e := range extra {
+		attr, err = newCmsAttribute(e.Type, e.Value)
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, attr)
+	}
+	return marshalCmsAttributes(attrs)
+}
+
+// コンテンツを読み込みながらダイジェスト値を計算します
+func streamDigest(r io.Reader, hash crypto.Hash) ([]byte, error) {
+	h := hash.New()
+	_, err := io.Copy(h, r)
+	if err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// 署名値を検証し、署名者の証明書をintermediatesの中間証明書を使ってrootsまで検証します
+func verifyCmsWithIntermediates(p7 *pkcs7.PKCS7, roots *x509.CertPool,
+	intermediates *x509.CertPool) error {
+
+	err := p7.Verify()
+	if err != nil {
+		return err
+	}
+	signer := p7.GetOnlySigner()
+	if signer == nil {
+		return newError("SIGNER_NOT_FOUND", nil)
+	}
+	signingTime := time.Now()
+	var attrTime time.Time
+	if p7.UnmarshalSignedAttribute(pkcs7.OIDAttributeSigningTime, &attrTime) == nil {
+		signingTime = attrTime
+	}
+	_, err = signer.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   signingTime,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err
+}
+
+// 署名者の証明書が指定したシリアル番号・Subjectと一致するかを確認します
+// serialがnil、subjectが空の場合はその項目を確認しません
+func checkExpectedSigner(signer *x509.Certificate, serial *big.Int, subject string) error {
+	if serial == nil && subject == "" {
+		return nil
+	}
+	if signer == nil {
+		return newError("SIGNER_NOT_FOUND", nil)
+	}
+	if serial != nil && signer.SerialNumber.Cmp(serial) != 0 {
+		return newError("SIGNER_MISMATCH", nil, "serial", fmt.Sprintf("%X", signer.SerialNumber))
+	}
+	if subject != "" && signer.Subject.String() != subject {
+		return newError("SIGNER_MISMATCH", nil, "subject", signer.Subject.String())
+	}
+	return nil
+}
+
+// 作成した署名を証明書の公開鍵で検証します
+// カードが不正な署名値を返した場合に検出するためのものです
+func selfVerifyCms(signed []byte, cert *x509.Certificate) error {
+	p7, err := pkcs7.Parse(signed)
+	if err != nil {
+		return newError("SELF_VERIFY_FAILED", err)
+	}
+	if signer := p7.GetOnlySigner(); signer == nil || !signer.Equal(cert) {
+		return newError("SELF_VERIFY_FAILED", nil)
+	}
+	if err = p7.Verify(); err != nil {
+		return newError("SELF_VERIFY_FAILED", err)
+	}
+	return nil
+}
+
+// デタッチ署名をコンテンツのダイジェスト値と証明書の公開鍵で検証します
+func selfVerifyDetachedCms(signed []byte, cert *x509.Certificate,
+	hash crypto.Hash, digest []byte) error {
+
+	// subjectKeyIdentifierの署名はpkcs7.Parseで読めないため、自前で解析します
+	sd, err := parseCmsSignedData(signed)
+	if err != nil {
+		return newError("SELF_VERIFY_FAILED", err)
+	}
+	if len(sd.SignerInfos) != 1 {
+		return newError("SELF_VERIFY_FAILED", nil)
+	}
+	err = verifyCmsSignerInfo(&sd.SignerInfos[0], cert, hash, digest)
+	if err != nil {
+		return newError("SELF_VERIFY_FAILED", err)
+	}
+	return nil
+}
+
+func parseCmsSignedData(signed []byte) (*cmsSignedData, error) {
+	var outer ContentInfo
+	_, err := asn1.Unmarshal(signed, &outer)
+	if err != nil {
+		return nil, err
+	}
+	var sd cmsSignedData
+	if _, err = asn1.Unmarshal(outer.Content.Bytes, &sd); err != nil {
+		return nil, err
+	}
+	return &sd, nil
+}
+
+// 最初の署名者がsubjectKeyIdentifierで証明書を参照しているか判定します
+// その場合はpkcs7.Parseで解析できないため、verifySubjectKeyIDCmsなどで自前で扱います
+func isSubjectKeyIDCms(signed []byte) bool {
+	sd, err := parseCmsSignedData(signed)
+	if err != nil || len(sd.SignerInfos) == 0 {
+		return false
+	}
+	sid := sd.SignerInfos[0].SignerIdentifier
+	return sid.Class == asn1.ClassContextSpecific && sid.Tag == 0
+}
+
+// 署名対象の属性のmessageDigestがdigestと一致し、署名値がcertの公開鍵で検証できるか確認します
+func verifyCmsSignerInfo(si *cmsSignerInfo, cert *x509.Certificate,
+	hash crypto.Hash, digest []byte) error {
+
+	var messageDigest []byte
+	err := unmarshalCmsAttribute(si.AuthenticatedAttributes.Bytes,
+		pkcs7.OIDAttributeMessageDigest, &messageDigest)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(messageDigest, digest) {
+		return newError("CMS_DIGEST_MISMATCH", nil)
+	}
+	toBeSigned, err := asn1.Marshal(asn1.RawValue{
+		Tag: asn1.TagSet, IsCompound: true, Bytes: si.AuthenticatedAttributes.Bytes})
+	if err != nil {
+		return err
+	}
+	h := hash.New()
+	h.Write(toBeSigned)
+	return VerifyRawSignature(cert, hash, h.Sum(nil), si.EncryptedDigest)
+}
+
+// subjectKeyIdentifierで署名者を参照するデタッチ署名を検証します
+// 署名者の証明書はcertificatesからsubjectKeyIdentifierが一致するものを探し、
+// rootsまでのチェーン・期待した署名者・タイムスタンプをpkcs7.Parseで読める署名と同様に確認します
+// 署名者の証明書とタイムスタンプの時刻(トークンが無い場合はnil)を返します
+func verifySubjectKeyIDCms(signed []byte, content []byte, roots *x509.CertPool,
+	opts CmsVerifyOpts) (*x509.Certificate, *time.Time, error) {
+
+	sd, err := parseCmsSignedData(signed)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(sd.SignerInfos) != 1 {
+		return nil, nil, newError("SIGNER_NOT_FOUND", nil)
+	}
+	si := &sd.SignerInfos[0]
+	certs, err := x509.ParseCertificates(sd.Certificates.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	var signer *x509.Certificate
+	intermediates := opts.Intermediates
+	if intermediates == nil {
+		intermediates = x509.NewCertPool()
+	}
+	for _, cert := range certs {
+		if bytes.Equal(cert.SubjectKeyId, si.SignerIdentifier.Bytes) {
+			signer = cert
+		} else if opts.Intermediates == nil {
+			intermediates.AddCert(cert)
+		}
+	}
+	if signer == nil {
+		return nil, nil, newError("SIGNER_NOT_FOUND", nil)
+	}
+
+	hash, err := tstHashForOID(si.DigestAlgorithm.Algorithm)
+	if err != nil {
+		return nil, nil, err
+	}
+	h := hash.New()
+	h.Write(content)
+	if err = verifyCmsSignerInfo(si, signer, hash, h.Sum(nil)); err != nil {
+		return nil, nil, err
+	}
+
+	signingTime := time.Now()
+	var attrTime time.Time
+	err = unmarshalCmsAttribute(si.AuthenticatedAttributes.Bytes,
+		pkcs7.OIDAttributeSigningTime, &attrTime)
+	if err == nil {
+		signingTime = attrTime
+	}
+	_, err = signer.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   signingTime,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	err = checkExpectedSigner(signer, opts.ExpectedSerial, opts.ExpectedSubject)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var genTime *time.Time
+	attrs := si.UnauthenticatedAttributes.Bytes
+	for len(attrs) > 0 {
+		var attr cmsAttribute
+		attrs, err = asn1.Unmarshal(attrs, &attr)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !attr.Type.Equal(oidAttributeTimeStampToken) {
+			continue
+		}
+		t, err := verifyTimestampToken(attr.Value.Bytes, si.EncryptedDigest, opts.TSARoots)
+		if err != nil {
+			return nil, nil, err
+		}
+		genTime = &t
+	}
+	return signer, genTime, nil
+}
+
+// 連結された属性からoidの属性を探し、その値をvalに読み込みます
+func unmarshalCmsAttribute(attrs []byte, oid asn1.ObjectIdentifier, val interface{}) error {
+	for len(attrs) > 0 {
+		var attr cmsAttribute
+		rest, err := asn1.Unmarshal(attrs, &attr)
+		if err != nil {
+			return err
+		}
+		if attr.Type.Equal(oid) {
+			_, err = asn1.Unmarshal(attr.Value.Bytes, val)
+			return err
+		}
+		attrs = rest
+	}
+	return newError("ATTRIBUTE_NOT_FOUND", nil, oid.String())
+}
@@ -0,0 +1,391 @@
+package libmyna
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/yu-ichiro/pkcs7"
+)
+
+func newTestCert(t *testing.T) (*x509.Certificate, *rsa.PrivateKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageContentCommitment,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func TestBuildDetachedCms(t *testing.T) {
+	cert, key := newTestCert(t)
+	content := []byte("hello myna")
+	digest, err := streamDigest(bytes.NewReader(content), crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := buildDetachedCms(cert, nil, key, crypto.SHA256,
+		pkcs7.OIDDigestAlgorithmSHA256, digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p7, err := pkcs7.Parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p7.Content = content
+	if err = p7.Verify(); err != nil {
+		t.Error(err)
+	}
+	p7.Content = []byte("tampered")
+	if err = p7.Verify(); err == nil {
+		t.Error("verification should fail for modified content")
+	}
+}
+
+func TestSupportedDigests(t *testing.T) {
+	for _, name := range SupportedDigests() {
+		if _, err := GetDigestOID(name); err != nil {
+			t.Error(err)
+		}
+		if _, err := GetDigestHash(name); err != nil {
+			t.Error(err)
+		}
+	}
+	if _, err := GetDigestHash("md5"); err == nil {
+		t.Error("GetDigestHash should fail: md5")
+	}
+}
+
+func TestContentDigest(t *testing.T) {
+	content := []byte("hello myna")
+	digest, err := ContentDigest(bytes.NewReader(content), "SHA256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := sha256.Sum256(content)
+	if !bytes.Equal(digest, expected[:]) {
+		t.Errorf("ContentDigest = %X, want %X", digest, expected)
+	}
+}
+
+func TestBuildDetachedCmsWithChain(t *testing.T) {
+	cert, key := newTestCert(t)
+	ca, _ := newTestCert(t)
+	digest := sha256.Sum256([]byte("hello myna"))
+	signed, err := buildDetachedCms(cert, []*x509.Certificate{ca}, key,
+		crypto.SHA256, pkcs7.OIDDigestAlgorithmSHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	p7, err := pkcs7.Parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p7.Certificates) != 2 {
+		t.Errorf("certificates = %d, want 2", len(p7.Certificates))
+	}
+}
+
+func TestSelfVerifyDetachedCms(t *testing.T) {
+	cert, key := newTestCert(t)
+	digest := sha256.Sum256([]byte("hello myna"))
+	signed, err := buildDetachedCms(cert, nil, key, crypto.SHA256,
+		pkcs7.OIDDigestAlgorithmSHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = selfVerifyDetachedCms(signed, cert, crypto.SHA256, digest[:]); err != nil {
+		t.Error(err)
+	}
+	other, _ := newTestCert(t)
+	if err = selfVerifyDetachedCms(signed, other, crypto.SHA256, digest[:]); err == nil {
+		t.Error("self verification should fail for another key")
+	}
+	wrong := sha256.Sum256([]byte("tampered"))
+	if err = selfVerifyDetachedCms(signed, cert, crypto.SHA256, wrong[:]); err == nil {
+		t.Error("self verification should fail for another digest")
+	}
+}
+
+func TestBuildDetachedCmsSubjectKeyID(t *testing.T) {
+	cert, key := newTestCert(t)
+	digest := sha256.Sum256([]byte("hello myna"))
+	_, err := buildDetachedCmsWithSignerID(cert, nil, key, SignerIDSubjectKeyID,
+		crypto.SHA256, pkcs7.OIDDigestAlgorithmSHA256, digest[:])
+	if !errors.Is(err, newError("NO_SUBJECT_KEY_ID", nil)) {
+		t.Errorf("buildDetachedCmsWithSignerID without SKI = %v", err)
+	}
+
+	cert.SubjectKeyId = []byte{1, 2, 3, 4}
+	signed, err := buildDetachedCmsWithSignerID(cert, nil, key, SignerIDSubjectKeyID,
+		crypto.SHA256, pkcs7.OIDDigestAlgorithmSHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var outer ContentInfo
+	if _, err = asn1.Unmarshal(signed, &outer); err != nil {
+		t.Fatal(err)
+	}
+	var sd cmsSignedData
+	if _, err = asn1.Unmarshal(outer.Content.Bytes, &sd); err != nil {
+		t.Fatal(err)
+	}
+	si := sd.SignerInfos[0]
+	if sd.Version != 3 || si.Version != 3 {
+		t.Errorf("version = %d/%d, want 3", sd.Version, si.Version)
+	}
+	if si.SignerIdentifier.Class != asn1.ClassContextSpecific ||
+		!bytes.Equal(si.SignerIdentifier.Bytes, cert.SubjectKeyId) {
+		t.Errorf("sid = % X", si.SignerIdentifier.FullBytes)
+	}
+	if err = selfVerifyDetachedCms(signed, cert, crypto.SHA256, digest[:]); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestVerifySubjectKeyIDCms(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageContentCommitment,
+		SubjectKeyId: []byte{1, 2, 3, 4},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := []byte("hello myna")
+	digest := sha256.Sum256(content)
+	signed, err := buildDetachedCmsWithSignerID(cert, nil, key, SignerIDSubjectKeyID,
+		crypto.SHA256, pkcs7.OIDDigestAlgorithmSHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isSubjectKeyIDCms(signed) {
+		t.Fatal("isSubjectKeyIDCms should detect the subjectKeyIdentifier")
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	signer, genTime, err := verifySubjectKeyIDCms(signed, content, roots, CmsVerifyOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !signer.Equal(cert) || genTime != nil {
+		t.Errorf("signer = %v, genTime = %v", signer.Subject, genTime)
+	}
+	_, _, err = verifySubjectKeyIDCms(signed, []byte("tampered"), roots, CmsVerifyOpts{})
+	if !errors.Is(err, newError("CMS_DIGEST_MISMATCH", nil)) {
+		t.Errorf("err = %v, want CMS_DIGEST_MISMATCH", err)
+	}
+	_, _, err = verifySubjectKeyIDCms(signed, content, x509.NewCertPool(), CmsVerifyOpts{})
+	if err == nil {
+		t.Error("verification should fail for an untrusted signer")
+	}
+	_, _, err = verifySubjectKeyIDCms(signed, content, roots,
+		CmsVerifyOpts{ExpectedSerial: big.NewInt(2)})
+	if !errors.Is(err, newError("SIGNER_MISMATCH", nil)) {
+		t.Errorf("err = %v, want SIGNER_MISMATCH", err)
+	}
+
+	desc, err := DescribeCMS(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(desc.Signers) != 1 || desc.Signers[0].SubjectKeyID != "01020304" || !desc.Detached {
+		t.Errorf("unexpected description: %+v", desc)
+	}
+}
+
+func TestSelfVerifyCms(t *testing.T) {
+	cert, key := newTestCert(t)
+	toBeSigned, err := pkcs7.NewSignedData([]byte("hello myna"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = toBeSigned.AddSigner(cert, key, pkcs7.SignerInfoConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	signed, err := toBeSigned.Finish()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = selfVerifyCms(signed, cert); err != nil {
+		t.Error(err)
+	}
+	other, _ := newTestCert(t)
+	if err = selfVerifyCms(signed, other); err == nil {
+		t.Error("self verification should fail for another certificate")
+	}
+}
+
+func TestBuildDetachedCmsWithCommitmentType(t *testing.T) {
+	cert, key := newTestCert(t)
+	digest := sha256.Sum256([]byte("hello myna"))
+	signed, err := buildDetachedCms(cert, nil, key, crypto.SHA256,
+		pkcs7.OIDDigestAlgorithmSHA256, digest[:],
+		CommitmentTypeAttribute(OIDCommitmentProofOfApproval))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = selfVerifyDetachedCms(signed, cert, crypto.SHA256, digest[:]); err != nil {
+		t.Error(err)
+	}
+	p7, err := pkcs7.Parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var indication cmsCommitmentTypeIndication
+	err = p7.UnmarshalSignedAttribute(OIDAttributeCommitmentType, &indication)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !indication.CommitmentTypeID.Equal(OIDCommitmentProofOfApproval) {
+		t.Errorf("commitment type = %s", indication.CommitmentTypeID)
+	}
+}
+
+func TestNewCmsSignResult(t *testing.T) {
+	cert, key := newTestCert(t)
+	digest := sha256.Sum256([]byte("hello myna"))
+	signed, err := buildDetachedCms(cert, nil, key, crypto.SHA256,
+		pkcs7.OIDDigestAlgorithmSHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := newCmsSignResult(signed, cert, "SHA256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(result.MessageDigest, digest[:]) {
+		t.Errorf("MessageDigest = %X, want %X", result.MessageDigest, digest)
+	}
+	if result.CertFingerprint != NewCertSummary(cert).SHA256 {
+		t.Errorf("unexpected fingerprint: %s", result.CertFingerprint)
+	}
+}
+
+func TestVerifyCmsWithIntermediates(t *testing.T) {
+	cert, key := newTestCert(t)
+	toBeSigned, err := pkcs7.NewSignedData([]byte("hello myna"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = toBeSigned.AddSigner(cert, key, pkcs7.SignerInfoConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	signed, err := toBeSigned.Finish()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p7, err := pkcs7.Parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	if err = verifyCmsWithIntermediates(p7, roots, x509.NewCertPool()); err != nil {
+		t.Error(err)
+	}
+	other, _ := newTestCert(t)
+	otherRoots := x509.NewCertPool()
+	otherRoots.AddCert(other)
+	if err = verifyCmsWithIntermediates(p7, otherRoots, x509.NewCertPool()); err == nil {
+		t.Error("verification should fail for an untrusted root")
+	}
+}
+
+func TestCheckExpectedSigner(t *testing.T) {
+	cert, _ := newTestCert(t)
+	if err := checkExpectedSigner(cert, nil, ""); err != nil {
+		t.Error(err)
+	}
+	if err := checkExpectedSigner(cert, big.NewInt(1), "CN=test"); err != nil {
+		t.Error(err)
+	}
+	if err := checkExpectedSigner(cert, big.NewInt(2), ""); err == nil {
+		t.Error("checkExpectedSigner should fail for another serial")
+	}
+	if err := checkExpectedSigner(cert, nil, "CN=other"); err == nil {
+		t.Error("checkExpectedSigner should fail for another subject")
+	}
+	if err := checkExpectedSigner(nil, big.NewInt(1), ""); err == nil {
+		t.Error("checkExpectedSigner should fail without a signer")
+	}
+}
+
+func TestDefaultDigestForKey(t *testing.T) {
+	cert, _ := newTestCert(t)
+	if got := DefaultDigestForKey(cert.PublicKey); got != "sha256" {
+		t.Errorf("DefaultDigestForKey(RSA-2048) = %s", got)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := DefaultDigestForKey(ecKey.Public()); got != "sha384" {
+		t.Errorf("DefaultDigestForKey(P-384) = %s", got)
+	}
+	alg, err := resolveDigest("SHA512", cert)
+	if err != nil || alg.hash != crypto.SHA512 {
+		t.Errorf("resolveDigest should keep the explicit hash: %v %v", alg, err)
+	}
+}
+
+func TestConfirmSigner(t *testing.T) {
+	cert, _ := newTestCert(t)
+	if err := confirmSigner(nil, cert); err != nil {
+		t.Errorf("confirmSigner(nil) = %v", err)
+	}
+	var name string
+	err := confirmSigner(func(signer *SignCertSubject) (bool, error) {
+		name = signer.Name
+		return false, nil
+	}, cert)
+	if !errors.Is(err, ErrSignCanceled) {
+		t.Errorf("confirmSigner = %v, want ErrSignCanceled", err)
+	}
+	if name != "test" {
+		t.Errorf("SignCertSubject.Name = %s", name)
+	}
+	err = confirmSigner(func(*SignCertSubject) (bool, error) { return true, nil }, cert)
+	if err != nil {
+		t.Errorf("confirmSigner = %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package libmyna
 
 import (
 	"crypto"
+	"crypto/rsa"
 	"crypto/x509/pkix"
 	"encoding/asn1"
 	"fmt"
@@ -59,6 +60,23 @@ func makeDigestInfo(hashid crypto.Hash, digest []byte) []byte {
 	return append(prefix, digest...)
 }
 
+// crypto.Signerに渡されたハッシュ済みの値からDigestInfoを作成します
+// カードの署名はPKCS#1 v1.5のためPSSは扱えません
+func signerDigestInfo(digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if _, ok := opts.(*rsa.PSSOptions); ok {
+		return nil, newError("PSS_UNSUPPORTED", nil)
+	}
+	hash := opts.HashFunc()
+	if _, ok := digestInfoPrefix[hash]; !ok {
+		return nil, newError("UNSUPPORTED_DIGEST", nil,
+			hash.String(), strings.Join(SupportedDigests(), ", "))
+	}
+	if len(digest) != hash.Size() {
+		return nil, newError("INVALID_DIGEST_SIZE", nil, len(digest), hash.String(), hash.Size())
+	}
+	return makeDigestInfo(hash, digest), nil
+}
+
 type ContentInfo struct {
 	ContentType asn1.ObjectIdentifier
 	Content     asn1.RawValue `asn1:"explicit,optional,tag:0"`
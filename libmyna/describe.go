@@ -0,0 +1,253 @@
+// CMS Description
+
+package libmyna
+
+import (
+	"bytes"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yu-ichiro/pkcs7"
+)
+
+// 署名者情報の属性
+type CMSAttribute struct {
+	OID   string
+	Name  string // 既知の属性の場合はその名前
+	Value string // 表示用に変換した値 (解釈できない場合はHEX文字列)
+}
+
+type CMSSignerDescription struct {
+	Issuer             string
+	Serial             string // 16進数
+	SubjectKeyID       string // 16進数 (sidがsubjectKeyIdentifierの場合のみ。Issuer・Serialは空です)
+	DigestAlgorithm    string
+	SignatureAlgorithm string
+	SignedAttributes   []CMSAttribute
+	UnsignedAttributes []CMSAttribute
+}
+
+// CMS SignedDataの構造を表示用にまとめたもの
+// 署名の検証は行いません
+type CMSDescription struct {
+	Detached     bool // コンテンツを含まない
+	ContentSize  int
+	Certificates []*CertSummary
+	Signers      []CMSSignerDescription
+}
+
+var cmsOIDNames = map[string]string{
+	pkcs7.OIDData.String():                         "data",
+	pkcs7.OIDAttributeContentType.String():         "contentType",
+	pkcs7.OIDAttributeMessageDigest.String():       "messageDigest",
+	pkcs7.OIDAttributeSigningTime.String():         "signingTime",
+	OIDAttributeCommitmentType.String():            "commitmentTypeIndication",
+	OIDCommitmentProofOfOrigin.String():            "proofOfOrigin",
+	OIDCommitmentProofOfApproval.String():          "proofOfApproval",
+	OIDCommitmentProofOfCreation.String():          "proofOfCreation",
+	oidAttributeTimeStampToken.String():            "timeStampToken",
+	pkcs7.OIDDigestAlgorithmSHA1.String():          "sha1",
+	pkcs7.OIDDigestAlgorithmSHA256.String():        "sha256",
+	pkcs7.OIDDigestAlgorithmSHA384.String():        "sha384",
+	pkcs7.OIDDigestAlgorithmSHA512.String():        "sha512",
+	pkcs7.OIDEncryptionAlgorithmRSA.String():       "rsaEncryption",
+	pkcs7.OIDEncryptionAlgorithmRSASHA256.String(): "sha256WithRSAEncryption",
+}
+
+func cmsOIDName(oid asn1.ObjectIdentifier) string {
+	if name, ok := cmsOIDNames[oid.String()]; ok {
+		return name
+	}
+	return oid.String()
+}
+
+// DERまたはPEM形式のCMS SignedDataを解析し、構造を返します
+func DescribeCMS(data []byte) (*CMSDescription, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		data = block.Bytes
+	}
+	if isSubjectKeyIDCms(data) {
+		return describeSubjectKeyIDCms(data)
+	}
+	p7, err := pkcs7.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	desc := CMSDescription{
+		Detached:    len(p7.Content) == 0,
+		ContentSize: len(p7.Content),
+	}
+	for _, cert := range p7.Certificates {
+		desc.Certificates = append(desc.Certificates, NewCertSummary(cert))
+	}
+	for _, signer := range p7.Signers {
+		var issuer pkix.RDNSequence
+		issuerName := fmt.Sprintf("% X", signer.IssuerAndSerialNumber.IssuerName.FullBytes)
+		if _, err := asn1.Unmarshal(signer.IssuerAndSerialNumber.IssuerName.FullBytes, &issuer); err == nil {
+			issuerName = issuer.String()
+		}
+		sd := CMSSignerDescription{
+			Issuer:             issuerName,
+			Serial:             fmt.Sprintf("%X", signer.IssuerAndSerialNumber.SerialNumber),
+			DigestAlgorithm:    cmsOIDName(signer.DigestAlgorithm.Algorithm),
+			SignatureAlgorithm: cmsOIDName(signer.DigestEncryptionAlgorithm.Algorithm),
+		}
+		for _, attr := range signer.AuthenticatedAttributes {
+			sd.SignedAttributes = append(sd.SignedAttributes,
+				describeCmsAttribute(attr.Type, attr.Value.Bytes))
+		}
+		for _, attr := range signer.UnauthenticatedAttributes {
+			sd.UnsignedAttributes = append(sd.UnsignedAttributes,
+				describeCmsAttribute(attr.Type, attr.Value.Bytes))
+		}
+		desc.Signers = append(desc.Signers, sd)
+	}
+	return &desc, nil
+}
+
+// pkcs7.Parseで読めないsubjectKeyIdentifierの署名を自前で解析して構造を返します
+func describeSubjectKeyIDCms(data []byte) (*CMSDescription, error) {
+	var outer ContentInfo
+	_, err := asn1.Unmarshal(data, &outer)
+	if err != nil {
+		return nil, err
+	}
+	var sd cmsSignedData
+	if _, err = asn1.Unmarshal(outer.Content.Bytes, &sd); err != nil {
+		return nil, err
+	}
+	var content []byte
+	if len(sd.ContentInfo.Content.Bytes) > 0 {
+		if _, err = asn1.Unmarshal(sd.ContentInfo.Content.Bytes, &content); err != nil {
+			return nil, err
+		}
+	}
+	desc := CMSDescription{
+		Detached:    len(content) == 0,
+		ContentSize: len(content),
+	}
+	certs, err := x509.ParseCertificates(sd.Certificates.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	for _, cert := range certs {
+		desc.Certificates = append(desc.Certificates, NewCertSummary(cert))
+	}
+	for _, si := range sd.SignerInfos {
+		signer := CMSSignerDescription{
+			SubjectKeyID:       fmt.Sprintf("%X", si.SignerIdentifier.Bytes),
+			DigestAlgorithm:    cmsOIDName(si.DigestAlgorithm.Algorithm),
+			SignatureAlgorithm: cmsOIDName(si.DigestEncryptionAlgorithm.Algorithm),
+		}
+		signer.SignedAttributes, err = describeCmsAttributes(si.AuthenticatedAttributes.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer.UnsignedAttributes, err = describeCmsAttributes(si.UnauthenticatedAttributes.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		desc.Signers = append(desc.Signers, signer)
+	}
+	return &desc, nil
+}
+
+// 連結された属性をそれぞれ表示用に変換します
+func describeCmsAttributes(attrs []byte) ([]CMSAttribute, error) {
+	var described []CMSAttribute
+	for len(attrs) > 0 {
+		var attr cmsAttribute
+		rest, err := asn1.Unmarshal(attrs, &attr)
+		if err != nil {
+			return nil, err
+		}
+		described = append(described, describeCmsAttribute(attr.Type, attr.Value.Bytes))
+		attrs = rest
+	}
+	return described, nil
+}
+
+// 属性の値(SETの中身)を表示用の文字列に変換します
+func describeCmsAttribute(oid asn1.ObjectIdentifier, value []byte) CMSAttribute {
+	attr := CMSAttribute{
+		OID:   oid.String(),
+		Name:  cmsOIDName(oid),
+		Value: fmt.Sprintf("% X", value),
+	}
+	switch {
+	case oid.Equal(pkcs7.OIDAttributeContentType):
+		var contentType asn1.ObjectIdentifier
+		if _, err := asn1.Unmarshal(value, &contentType); err == nil {
+			attr.Value = cmsOIDName(contentType)
+		}
+	case oid.Equal(pkcs7.OIDAttributeMessageDigest):
+		var digest []byte
+		if _, err := asn1.Unmarshal(value, &digest); err == nil {
+			attr.Value = fmt.Sprintf("%x", digest)
+		}
+	case oid.Equal(pkcs7.OIDAttributeSigningTime):
+		var signingTime time.Time
+		if _, err := asn1.Unmarshal(value, &signingTime); err == nil {
+			attr.Value = signingTime.UTC().Format(time.RFC3339)
+		}
+	case oid.Equal(OIDAttributeCommitmentType):
+		var commitment cmsCommitmentTypeIndication
+		if _, err := asn1.Unmarshal(value, &commitment); err == nil {
+			attr.Value = cmsOIDName(commitment.CommitmentTypeID)
+		}
+	case oid.Equal(oidAttributeTimeStampToken):
+		token, err := pkcs7.Parse(value)
+		if err != nil {
+			break
+		}
+		var info tstInfo
+		if _, err = asn1.Unmarshal(token.Content, &info); err == nil {
+			attr.Value = "genTime " + info.GenTime.UTC().Format(time.RFC3339) + " (未検証)"
+		}
+	}
+	return attr
+}
+
+func (self *CMSDescription) String() string {
+	var b bytes.Buffer
+	if self.Detached {
+		fmt.Fprintf(&b, "Content: detached\n")
+	} else {
+		fmt.Fprintf(&b, "Content: %d bytes\n", self.ContentSize)
+	}
+	for i, cert := range self.Certificates {
+		fmt.Fprintf(&b, "Certificate %d:\n", i)
+		fmt.Fprintf(&b, "  Subject: %s\n", cert.Subject)
+		fmt.Fprintf(&b, "  Issuer:  %s\n", cert.Issuer)
+		fmt.Fprintf(&b, "  Serial:  %s\n", cert.Serial)
+	}
+	for i, signer := range self.Signers {
+		fmt.Fprintf(&b, "Signer %d:\n", i)
+		if signer.SubjectKeyID != "" {
+			fmt.Fprintf(&b, "  SKI:       %s\n", signer.SubjectKeyID)
+		} else {
+			fmt.Fprintf(&b, "  Issuer:    %s\n", signer.Issuer)
+			fmt.Fprintf(&b, "  Serial:    %s\n", signer.Serial)
+		}
+		fmt.Fprintf(&b, "  Digest:    %s\n", signer.DigestAlgorithm)
+		fmt.Fprintf(&b, "  Signature: %s\n", signer.SignatureAlgorithm)
+		writeCmsAttributes(&b, "Signed attributes", signer.SignedAttributes)
+		writeCmsAttributes(&b, "Unsigned attributes", signer.UnsignedAttributes)
+	}
+	return b.String()
+}
+
+func writeCmsAttributes(b *bytes.Buffer, title string, attrs []CMSAttribute) {
+	if len(attrs) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "  %s:\n", title)
+	for _, attr := range attrs {
+		fmt.Fprintf(b, "    %s: %s\n", attr.Name, strings.TrimSpace(attr.Value))
+	}
+}
@@ -0,0 +1,42 @@
+package libmyna
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"strings"
+	"testing"
+
+	"github.com/yu-ichiro/pkcs7"
+)
+
+func TestDescribeCMS(t *testing.T) {
+	cert, key := newTestCert(t)
+	digest := sha256.Sum256([]byte("hello myna"))
+	signed, err := buildDetachedCms(cert, nil, key, crypto.SHA256,
+		pkcs7.OIDDigestAlgorithmSHA256, digest[:],
+		CommitmentTypeAttribute(OIDCommitmentProofOfOrigin))
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc, err := DescribeCMS(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !desc.Detached || len(desc.Certificates) != 1 || len(desc.Signers) != 1 {
+		t.Fatalf("unexpected description: %+v", desc)
+	}
+	signer := desc.Signers[0]
+	if signer.DigestAlgorithm != "sha256" || signer.Serial != "1" {
+		t.Errorf("unexpected signer: %+v", signer)
+	}
+	values := map[string]string{}
+	for _, attr := range signer.SignedAttributes {
+		values[attr.Name] = attr.Value
+	}
+	if values["contentType"] != "data" || values["commitmentTypeIndication"] != "proofOfOrigin" {
+		t.Errorf("unexpected attributes: %v", values)
+	}
+	if !strings.Contains(desc.String(), "messageDigest: ") {
+		t.Errorf("String() should list the message digest:\n%s", desc)
+	}
+}
@@ -0,0 +1,124 @@
+// Card Dump
+
+package libmyna
+
+import (
+	"crypto/x509"
+)
+
+type DumpOpts struct {
+	TextPin string // 券面事項入力補助用PIN (空の場合は個人番号と基本4情報を読み取りません)
+	SignPin string // 署名用パスワード (空の場合は署名用証明書を読み取りません)
+}
+
+// カードから読み取れた情報の一覧
+// 読み取りに失敗した項目はErrorsに項目名をキーとして理由を格納します
+type CardDump struct {
+	Token      string
+	PinStatus  map[string]int
+	AuthCert   *x509.Certificate
+	AuthCACert *x509.Certificate
+	SignCert   *x509.Certificate
+	SignCACert *x509.Certificate
+	MyNumber   string
+	Attrs      *TextAttrs
+	Errors     map[string]error
+}
+
+var errDumpNoPin = newError("PIN_NOT_GIVEN", nil)
+
+// カードの情報を1回の接続でまとめて読み取ります
+// 一部の項目の読み取りに失敗しても中断せず、読み取れた分を返します
+// エラーを返すのはカードに接続できない場合のみです
+func DumpCard(opts DumpOpts) (*CardDump, error) {
+	reader, err := NewReader(OptionDebug)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Finalize()
+	err = reader.Connect()
+	if err != nil {
+		return nil, err
+	}
+
+	dump := CardDump{Errors: map[string]error{}}
+	dump.PinStatus, err = readPinStatus(reader)
+	if err != nil {
+		dump.Errors["pin_status"] = err
+	}
+
+	jpkiAP, err := reader.SelectJPKIAP()
+	if err != nil {
+		for _, section := range []string{"token", "auth_cert",
+			"auth_ca_cert", "sign_ca_cert", "sign_cert"} {
+			dump.Errors[section] = err
+		}
+	} else {
+		dump.readJPKI(jpkiAP, &reader.profile, opts.SignPin)
+	}
+
+	textAP, err := reader.SelectTextAP()
+	if err != nil {
+		dump.Errors["mynumber"] = err
+		dump.Errors["attributes"] = err
+	} else {
+		dump.readText(textAP, opts.TextPin)
+	}
+	return &dump, nil
+}
+
+func (self *CardDump) readJPKI(jpkiAP *JPKIAP, profile *CardProfile, pin string) {
+	var err error
+	self.Token, err = jpkiAP.GetToken()
+	if err != nil {
+		self.Errors["token"] = err
+	}
+	self.AuthCert, err = jpkiAP.ReadCertificate(profile.AuthCertEF)
+	if err != nil {
+		self.Errors["auth_cert"] = err
+	}
+	self.AuthCACert, err = jpkiAP.ReadCertificate(profile.AuthCACertEF)
+	if err != nil {
+		self.Errors["auth_ca_cert"] = err
+	}
+	self.SignCACert, err = jpkiAP.ReadCertificate(profile.SignCACertEF)
+	if err != nil {
+		self.Errors["sign_ca_cert"] = err
+	}
+
+	if pin == "" {
+		self.Errors["sign_cert"] = errDumpNoPin
+		return
+	}
+	err = jpkiAP.VerifySignPin(pin)
+	if err != nil {
+		self.Errors["sign_cert"] = err
+		return
+	}
+	self.SignCert, err = jpkiAP.ReadCertificate(profile.SignCertEF)
+	if err != nil {
+		self.Errors["sign_cert"] = err
+	}
+}
+
+func (self *CardDump) readText(textAP *TextAP, pin string) {
+	if pin == "" {
+		self.Errors["mynumber"] = errDumpNoPin
+		self.Errors["attributes"] = errDumpNoPin
+		return
+	}
+	err := textAP.VerifyPin(pin)
+	if err != nil {
+		self.Errors["mynumber"] = err
+		self.Errors["attributes"] = err
+		return
+	}
+	self.MyNumber, err = textAP.ReadMyNumber()
+	if err != nil {
+		self.Errors["mynumber"] = err
+	}
+	self.Attrs, err = textAP.ReadAttributes()
+	if err != nil {
+		self.Errors["attributes"] = err
+	}
+}
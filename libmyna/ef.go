@@ -0,0 +1,144 @@
+// EF Enumeration
+
+package libmyna
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+type EFInfo struct {
+	ID   string // EF識別子 ("00 0A"など)
+	Size int    // FCIから取得したファイルサイズ (取得できない場合は-1)
+	Pin  bool   // PINのEF
+	FCI  []byte // SELECTの応答 (返さないカードもあります)
+}
+
+// 個人番号カードの既知のAPのAID
+const (
+	AIDVisualAP = "D3921000310001010402" // 券面AP
+	AIDTextAP   = "D3921000310001010408" // 券面事項入力補助AP
+	AIDJPKIAP   = "D392F000260100000001" // 公的個人認証AP
+)
+
+var apNames = map[string]string{
+	"VISUAL": AIDVisualAP,
+	"TEXT":   AIDTextAP,
+	"JPKI":   AIDJPKIAP,
+}
+
+// PINのEFかどうかを判定します
+// 既知のPINのEFに加えて、プロファイルに設定されたPINのEFも対象とします
+func (self *Reader) isPinEF(id string) bool {
+	normalized := strings.ToUpper(strings.Replace(id, " ", "", -1))
+	if _, ok := pinPolicies[normalized]; ok {
+		return true
+	}
+	for _, pinEF := range []string{self.profile.AuthPinEF, self.profile.SignPinEF} {
+		if strings.ToUpper(strings.Replace(pinEF, " ", "", -1)) == normalized {
+			return true
+		}
+	}
+	return false
+}
+
+// EFをSELECTし、FCIを要求します
+func (self *Reader) selectEFWithFCI(id string) ([]byte, error) {
+	if self.debug {
+		fmt.Fprintf(os.Stderr, "# Select EF (FCI)\n")
+	}
+	apdu := NewAPDUCase4(0x00, 0xA4, 0x02, 0x00, ToBytes(id), 0)
+	sw1, sw2, data := self.Trans(apdu)
+	if sw1 == 0x90 && sw2 == 0x00 {
+		return data, nil
+	}
+	return nil, self.apduError(sw1, sw2)
+}
+
+// EFをSELECTし、読み取るバイト数を判定します
+// FCIからファイルサイズを取得できた場合はその値を返します
+// FCIを返さないカードでは通常のSELECTに切り替え、先頭7バイトのTLVの長さから判定します
+// ファイルサイズは格納されたTLVより大きい場合があるため、呼び出し元で末尾を切り詰めてください
+func (self *Reader) selectEFSize(id string) (uint16, error) {
+	fci, err := self.selectEFWithFCI(id)
+	if err == nil {
+		size := parseFCISize(fci)
+		if size > 0 && size <= 0xFFFF {
+			if self.debug {
+				fmt.Fprintf(os.Stderr, "# EF size from FCI: %d\n", size)
+			}
+			return uint16(size), nil
+		}
+	}
+	if self.debug {
+		fmt.Fprintf(os.Stderr, "# FCI unavailable, probing EF size\n")
+	}
+	if err != nil {
+		err = self.SelectEF(id)
+		if err != nil {
+			return 0, err
+		}
+	}
+	data, err := self.readBinary(7, nil)
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 7 {
+		return 0, newError("READ_BINARY_FAILED", nil)
+	}
+	parser := ASN1PartialParser{}
+	err = parser.Parse(data)
+	if err != nil {
+		return 0, err
+	}
+	return parser.GetSize(), nil
+}
+
+// 読み取ったデータを先頭のTLVの長さに切り詰めます
+func trimTLV(data []byte) []byte {
+	parser := ASN1PartialParser{}
+	if parser.Parse(data) != nil || int(parser.GetSize()) > len(data) {
+		return data
+	}
+	return data[:parser.GetSize()]
+}
+
+// AP配下のEF識別子00 00から00 FFをSELECTして存在するEFを列挙します
+// apには"JPKI","TEXT","VISUAL"またはAIDのHEX文字列を指定します
+// SELECT以外のコマンドは送信しないため、PINのEFを列挙してもロックされることはありません
+func ListEFs(ap string) ([]EFInfo, error) {
+	aid, ok := apNames[strings.ToUpper(ap)]
+	if !ok {
+		aid = ap
+	}
+	reader, err := NewReader(OptionDebug)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Finalize()
+	err = reader.Connect()
+	if err != nil {
+		return nil, err
+	}
+	err = reader.selectAP(aid)
+	if err != nil {
+		return nil, err
+	}
+
+	var efs []EFInfo
+	for i := 0; i <= 0xFF; i++ {
+		id := fmt.Sprintf("00 %02X", i)
+		if reader.SelectEF(id) != nil {
+			continue
+		}
+		info := EFInfo{ID: id, Size: -1, Pin: reader.isPinEF(id)}
+		fci, err := reader.selectEFWithFCI(id)
+		if err == nil {
+			info.FCI = fci
+			info.Size = parseFCISize(fci)
+		}
+		efs = append(efs, info)
+	}
+	return efs, nil
+}
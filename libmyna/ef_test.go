@@ -0,0 +1,80 @@
+package libmyna
+
+import (
+	"testing"
+)
+
+func TestParseFCISize(t *testing.T) {
+	tests := []struct {
+		fci  string
+		size int
+	}{
+		{"62 04 80 02 07 D0", 2000},
+		{"6F 07 82 01 01 80 02 01 00", 256},
+		{"62 03 81 01 20", 0x20},
+		{"62 03 82 01 01", -1},
+		{"", -1},
+		{"90 00", -1},
+		{"62 08 80 02", -1},
+	}
+	for _, test := range tests {
+		got := parseFCISize(ToBytes(test.fci))
+		if got != test.size {
+			t.Errorf("parseFCISize(%s) = %d, want %d", test.fci, got, test.size)
+		}
+	}
+}
+
+func TestSelectEFSize(t *testing.T) {
+	ok := []byte{0x90, 0x00}
+	withFCI := mapTransport{
+		"00 A4 02 00 02 00 02 00": append(ToBytes("62 04 80 02 01 00"), ok...),
+	}
+	size, err := NewReaderWithTransport(withFCI).selectEFSize("00 02")
+	if err != nil || size != 0x100 {
+		t.Errorf("selectEFSize with FCI = %d, %v", size, err)
+	}
+
+	// FCIを返さないカードでは先頭のTLVから判定する
+	withoutFCI := mapTransport{
+		"00 A4 02 00 02 00 02 00": {0x6A, 0x86},
+		"00 A4 02 0C 02 00 02":    ok,
+		"00 B0 00 00 07":          append(ToBytes("30 82 01 00 30 82 00"), ok...),
+	}
+	size, err = NewReaderWithTransport(withoutFCI).selectEFSize("00 02")
+	if err != nil || size != 0x104 {
+		t.Errorf("selectEFSize without FCI = %d, %v", size, err)
+	}
+
+	if _, err = NewReaderWithTransport(mapTransport{}).selectEFSize("00 02"); err == nil {
+		t.Error("selectEFSize should fail for a missing EF")
+	}
+}
+
+func TestTrimTLV(t *testing.T) {
+	got := trimTLV(ToBytes("30 02 01 02 FF FF"))
+	if len(got) != 4 {
+		t.Errorf("trimTLV = % X", got)
+	}
+}
+
+func TestGetFCI(t *testing.T) {
+	card := mapTransport{
+		"00 A4 02 00 02 00 0A 00": append(ToBytes(
+			"62 15 80 02 07 D0 82 01 01 83 02 00 0A 88 01 50 8A 01 05 A5 02 01 02"),
+			0x90, 0x00),
+	}
+	fci, err := NewReaderWithTransport(card).GetFCI("00 0A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fci.Size != 2000 || fci.TotalSize != -1 || fci.FileType() != "transparent EF" {
+		t.Errorf("unexpected FCI: %+v", fci)
+	}
+	if fci.ShortEFID != 0x0A || fci.LifeCycle != 5 || len(fci.Proprietary[0xA5]) != 2 {
+		t.Errorf("unexpected FCI: %+v", fci)
+	}
+	if _, err = NewReaderWithTransport(mapTransport{}).GetFCI("00 0A"); err == nil {
+		t.Error("GetFCI should fail for a missing EF")
+	}
+}
@@ -0,0 +1,53 @@
+// Environment Information
+
+package libmyna
+
+import (
+	"runtime"
+	"runtime/debug"
+
+	"github.com/ebfe/scard"
+)
+
+type SystemInfo struct {
+	Version       string            `json:"version"`
+	GoVersion     string            `json:"go_version"`
+	OS            string            `json:"os"`
+	Arch          string            `json:"arch"`
+	PCSCVersion   string            `json:"pcsc_version"`
+	PCSCAvailable bool              `json:"pcsc_available"`
+	PCSCError     string            `json:"pcsc_error,omitempty"`
+	Readers       []string          `json:"readers"`
+	Modules       map[string]string `json:"modules,omitempty"` // 依存モジュールとバージョン
+}
+
+// 不具合報告のために実行環境の情報を収集します
+// PC/SCを利用できない場合もエラーは返さず、PCSCErrorに理由を格納します
+func GetSystemInfo() (*SystemInfo, error) {
+	info := SystemInfo{
+		Version:     Version,
+		GoVersion:   runtime.Version(),
+		OS:          runtime.GOOS,
+		Arch:        runtime.GOARCH,
+		PCSCVersion: PCSCVersion(),
+	}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		info.Modules = map[string]string{}
+		for _, dep := range build.Deps {
+			info.Modules[dep.Path] = dep.Version
+		}
+	}
+
+	ctx, err := scard.EstablishContext()
+	if err != nil {
+		info.PCSCError = err.Error()
+		return &info, nil
+	}
+	defer ctx.Release()
+	info.PCSCAvailable = true
+	info.Readers, err = ctx.ListReaders()
+	if err != nil {
+		info.PCSCError = err.Error()
+	}
+	return &info, nil
+}
@@ -0,0 +1,40 @@
+// Japanese Era
+
+package libmyna
+
+import (
+	"fmt"
+	"time"
+)
+
+var jst = time.FixedZone("JST", 9*60*60)
+
+// 元号と開始日 (新しい順)
+var japaneseEras = []struct {
+	name  string
+	start time.Time
+}{
+	{"令和", time.Date(2019, 5, 1, 0, 0, 0, 0, jst)},
+	{"平成", time.Date(1989, 1, 8, 0, 0, 0, 0, jst)},
+	{"昭和", time.Date(1926, 12, 25, 0, 0, 0, 0, jst)},
+	{"大正", time.Date(1912, 7, 30, 0, 0, 0, 0, jst)},
+	{"明治", time.Date(1868, 1, 25, 0, 0, 0, 0, jst)},
+}
+
+// 日時を日本時間の和暦で「令和元年5月1日」の形式に変換します
+// 明治より前の日付は西暦で返します
+func FormatJapaneseEra(t time.Time) string {
+	t = t.In(jst)
+	for _, era := range japaneseEras {
+		if t.Before(era.start) {
+			continue
+		}
+		year := t.Year() - era.start.Year() + 1
+		y := fmt.Sprintf("%d", year)
+		if year == 1 {
+			y = "元"
+		}
+		return fmt.Sprintf("%s%s年%d月%d日", era.name, y, t.Month(), t.Day())
+	}
+	return fmt.Sprintf("%d年%d月%d日", t.Year(), t.Month(), t.Day())
+}
@@ -0,0 +1,27 @@
+package libmyna
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatJapaneseEra(t *testing.T) {
+	tests := []struct {
+		t    time.Time
+		want string
+	}{
+		{time.Date(2019, 4, 30, 0, 0, 0, 0, jst), "平成31年4月30日"},
+		{time.Date(2019, 5, 1, 0, 0, 0, 0, jst), "令和元年5月1日"},
+		// UTCでは4月30日でも日本時間では令和になる
+		{time.Date(2019, 4, 30, 15, 0, 0, 0, time.UTC), "令和元年5月1日"},
+		{time.Date(2024, 3, 31, 0, 0, 0, 0, jst), "令和6年3月31日"},
+		{time.Date(1989, 1, 7, 0, 0, 0, 0, jst), "昭和64年1月7日"},
+		{time.Date(1989, 1, 8, 0, 0, 0, 0, jst), "平成元年1月8日"},
+		{time.Date(1800, 1, 1, 0, 0, 0, 0, jst), "1800年1月1日"},
+	}
+	for _, test := range tests {
+		if got := FormatJapaneseEra(test.t); got != test.want {
+			t.Errorf("FormatJapaneseEra(%v) = %s, want %s", test.t, got, test.want)
+		}
+	}
+}
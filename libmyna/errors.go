@@ -4,6 +4,98 @@ import (
 	"fmt"
 )
 
+// エラーコードと言語ごとのメッセージを持つエラー
+// errors.Isはコードが一致すれば真になるため、引数や原因が異なっても
+// ErrAPNotFoundなどのエラーと比較できます
+type Error struct {
+	Code string
+	Args []interface{}
+	Err  error // 原因となったエラー
+}
+
+func newError(code string, cause error, args ...interface{}) *Error {
+	return &Error{Code: code, Args: args, Err: cause}
+}
+
+func (self *Error) Error() string {
+	msg := localizedMessage(self.Code)
+	if len(self.Args) > 0 {
+		return fmt.Sprintf(msg, self.Args...)
+	}
+	return msg
+}
+
+func (self *Error) Unwrap() error {
+	return self.Err
+}
+
+func (self *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == self.Code
+}
+
+// SELECTしたAPがカードに存在しない場合のエラー
+// errors.Isで判定してください
+var ErrAPNotFound = newError("AP_NOT_FOUND", nil)
+
+// SafeVerifyで照合するとカードがロックされるおそれがある場合のエラー
+var ErrWouldLock = newError("WOULD_LOCK", nil, 1)
+
+// NewReaderStrictで使用するリーダーを特定できない場合のエラー
+var ErrMultipleReaders = newError("MULTIPLE_READERS", nil)
+
+// PINの照合が必要なEFを照合せずに読み取った場合のエラー (SW=6982)
+var ErrPinRequired = newError("PIN_REQUIRED", nil)
+
+// ReadOnlyが設定されているときにPINの変更や署名を行おうとした場合のエラー
+var ErrReadOnly = newError("READ_ONLY", nil)
+
+// CmsSignOpts.Confirmが署名を承認しなかった場合のエラー
+var ErrSignCanceled = newError("SIGN_CANCELED", nil)
+
+// 署名時に読み直した署名用証明書が確認した証明書と異なる場合のエラー
+var ErrSignCertMismatch = newError("SIGN_CERT_MISMATCH", nil)
+
+// trueにするとPINの変更と署名を行う操作はカードにコマンドを送らずにErrReadOnlyを返します
+// 公共端末などでカードを変更しないことを保証したい場合に設定してください
+var ReadOnly = false
+
+func checkReadOnly() error {
+	if ReadOnly {
+		return ErrReadOnly
+	}
+	return nil
+}
+
+// Reader.SetAPDUTimeoutで指定した時間内にカードが応答しなかった場合のエラー
+var ErrAPDUTimeout = newError("APDU_TIMEOUT", nil)
+
+// Transの結果のSWをエラーに変換します
+// 直前のAPDUがタイムアウトしていた場合はErrAPDUTimeoutと比較できるエラーを返します
+func (self *Reader) apduError(sw1 uint8, sw2 uint8) error {
+	if self.timeoutErr != nil {
+		return self.timeoutErr
+	}
+	return NewAPDUError(sw1, sw2)
+}
+
+func (self *Reader) readError(sw1 uint8, sw2 uint8) error {
+	if self.timeoutErr != nil {
+		return self.timeoutErr
+	}
+	return readBinaryError(sw1, sw2)
+}
+
+// READ BINARYのSWをエラーに変換します
+// SW=6982の場合はErrPinRequiredと比較できるエラーを返します
+func readBinaryError(sw1 uint8, sw2 uint8) error {
+	err := NewAPDUError(sw1, sw2)
+	if sw1 == 0x69 && sw2 == 0x82 {
+		return newError("PIN_REQUIRED", err)
+	}
+	return err
+}
+
 type APDUError struct {
 	sw1 uint8
 	sw2 uint8
@@ -16,3 +108,11 @@ func NewAPDUError(sw1 uint8, sw2 uint8) error {
 func (self *APDUError) Error() string {
 	return fmt.Sprintf("APDU Error SW1=%02X SW2=%02X", self.sw1, self.sw2)
 }
+
+func (self *APDUError) SW() (uint8, uint8) {
+	return self.sw1, self.sw2
+}
+
+func (self *APDUError) StatusWord() StatusWord {
+	return NewStatusWord(self.sw1, self.sw2)
+}
@@ -0,0 +1,52 @@
+package libmyna
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+	cause := NewAPDUError(0x63, 0xC2)
+	err := newError("PIN_INCORRECT", cause, 2)
+	if err.Error() != "暗証番号が間違っています。のこり2回" {
+		t.Errorf("unexpected message: %s", err)
+	}
+	var apduErr *APDUError
+	if !errors.As(err, &apduErr) {
+		t.Fatal("errors.As should find APDUError")
+	}
+	sw1, sw2 := apduErr.SW()
+	if sw1 != 0x63 || sw2 != 0xC2 {
+		t.Errorf("SW = %02X %02X, want 63 C2", sw1, sw2)
+	}
+
+	err = newError("NOT_MYNUMBER_CARD", newError("AP_NOT_FOUND", cause))
+	if !errors.Is(err, ErrAPNotFound) {
+		t.Error("errors.Is should find ErrAPNotFound")
+	}
+	if errors.Is(err, ErrMultipleReaders) {
+		t.Error("errors.Is should not match ErrMultipleReaders")
+	}
+}
+
+func TestSetLanguage(t *testing.T) {
+	defer SetLanguage("ja")
+	if err := SetLanguage("en"); err != nil {
+		t.Fatal(err)
+	}
+	err := newError("PIN_INCORRECT", nil, 2)
+	if err.Error() != "incorrect PIN, 2 attempts remaining" {
+		t.Errorf("unexpected message: %s", err)
+	}
+	if err := ValidatePin("JPKI_SIGN", "ABC"); err == nil || err.Error() != "invalid password length" {
+		t.Errorf("ValidatePin should fail with an English message: %v", err)
+	}
+	if err := SetLanguage("fr"); err == nil {
+		t.Error("SetLanguage should fail: fr")
+	}
+	for code := range messages["ja"] {
+		if _, ok := messages["en"][code]; !ok {
+			t.Errorf("missing English message: %s", code)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// File Control Information
+
+package libmyna
+
+import (
+	"fmt"
+)
+
+// SELECTで返されるFCI(FCP)テンプレートを解析したもの
+// 数値の項目はタグが無い場合は-1です
+type FCI struct {
+	Raw         []byte
+	Size        int             // タグ80 ファイルのデータのバイト数
+	TotalSize   int             // タグ81 構造情報を含むバイト数
+	Descriptor  []byte          // タグ82 ファイル記述子
+	FileID      []byte          // タグ83
+	DFName      []byte          // タグ84
+	ShortEFID   int             // タグ88 短縮EF識別子
+	LifeCycle   int             // タグ8A ライフサイクル状態
+	Proprietary map[byte][]byte // タグ85, 86, A5など上記以外の要素 (タグをキーとします)
+}
+
+// ファイル記述子の先頭バイトからファイルの種類を返します
+func (self *FCI) FileType() string {
+	if len(self.Descriptor) == 0 {
+		return "unknown"
+	}
+	fdb := self.Descriptor[0]
+	if fdb&0x38 == 0x38 {
+		return "DF"
+	}
+	switch fdb & 0x07 {
+	case 0x01:
+		return "transparent EF"
+	case 0x02, 0x03:
+		return "linear fixed EF"
+	case 0x04, 0x05:
+		return "linear variable EF"
+	case 0x06, 0x07:
+		return "cyclic EF"
+	default:
+		return fmt.Sprintf("EF (0x%02X)", fdb)
+	}
+}
+
+// FCI(タグ62)またはFCP(タグ6F)のテンプレートを解析します
+func parseFCI(data []byte) (*FCI, error) {
+	if len(data) == 0 || (data[0] != 0x62 && data[0] != 0x6F) {
+		return nil, newError("INVALID_FCI", nil)
+	}
+	parser := ASN1PartialParser{}
+	if parser.Parse(data) != nil || int(parser.GetSize()) > len(data) {
+		return nil, newError("INVALID_FCI", nil)
+	}
+	fci := FCI{
+		Raw:         data[:parser.GetSize()],
+		Size:        -1,
+		TotalSize:   -1,
+		ShortEFID:   -1,
+		LifeCycle:   -1,
+		Proprietary: map[byte][]byte{},
+	}
+	body := data[parser.GetOffset():parser.GetSize()]
+	for len(body) > 0 {
+		p := ASN1PartialParser{}
+		if p.Parse(body) != nil || int(p.GetSize()) > len(body) {
+			return nil, newError("INVALID_FCI", nil)
+		}
+		value := body[p.GetOffset():p.GetSize()]
+		switch body[0] {
+		case 0x80:
+			fci.Size = fciInt(value)
+		case 0x81:
+			fci.TotalSize = fciInt(value)
+		case 0x82:
+			fci.Descriptor = value
+		case 0x83:
+			fci.FileID = value
+		case 0x84:
+			fci.DFName = value
+		case 0x88:
+			// 長さ0の場合は短縮EF識別子に対応していない
+			if len(value) > 0 {
+				fci.ShortEFID = int(value[0] >> 3)
+			}
+		case 0x8A:
+			if len(value) > 0 {
+				fci.LifeCycle = int(value[0])
+			}
+		default:
+			fci.Proprietary[body[0]] = value
+		}
+		body = body[p.GetSize():]
+	}
+	return &fci, nil
+}
+
+func fciInt(value []byte) int {
+	n := 0
+	for _, b := range value {
+		n = n<<8 | int(b)
+	}
+	return n
+}
+
+// FCI(FCP)テンプレートのタグ80/81からファイルサイズを取り出します
+func parseFCISize(data []byte) int {
+	fci, err := parseFCI(data)
+	if err != nil {
+		return -1
+	}
+	if fci.Size >= 0 {
+		return fci.Size
+	}
+	return fci.TotalSize
+}
+
+// EFをFCIを要求してSELECTし、解析したFCIを返します
+// FCIを返さないカードではエラーになります
+func (self *Reader) GetFCI(efid string) (*FCI, error) {
+	data, err := self.selectEFWithFCI(efid)
+	if err != nil {
+		return nil, err
+	}
+	return parseFCI(data)
+}
@@ -0,0 +1,61 @@
+// GET DATA
+
+package libmyna
+
+import (
+	"fmt"
+)
+
+// GET DATA(00 CA)で読み取る既知のデータオブジェクトのタグ
+// GetData(ToBytes(DataObjectHistoricalBytes))のように指定します
+const (
+	DataObjectAID             = "4F"    // アプリケーション識別子
+	DataObjectCardData        = "66"    // カードデータ
+	DataObjectHistoricalBytes = "5F 52" // ヒストリカルバイト
+	DataObjectExtendedLength  = "7F 66" // 拡張長の情報
+)
+
+// PC/SCのGET DATA(FF CA)でリーダーから取得するデータのP1
+const (
+	ReaderDataUID             = 0x00 // 非接触カードのUID
+	ReaderDataHistoricalBytes = 0x01 // ATS(ATR)のヒストリカルバイト
+)
+
+// GET DATA(00 CA)でカードのデータオブジェクトを読み取ります
+// tagは1バイトまたは2バイトで、P1-P2に設定します
+// 応答長の誤り(6Cxx)の場合は指定された長さで再送します
+func (self *Reader) GetData(tag []byte) ([]byte, error) {
+	var p1, p2 uint8
+	switch len(tag) {
+	case 1:
+		p2 = tag[0]
+	case 2:
+		p1, p2 = tag[0], tag[1]
+	default:
+		return nil, newError("INVALID_DATA_TAG", nil, fmt.Sprintf("% X", tag))
+	}
+	return self.getData(0x00, p1, p2)
+}
+
+// PC/SCのGET DATA(FF CA)でリーダーが保持するデータを取得します
+// p1にはReaderDataUIDなどを指定します
+func (self *Reader) GetReaderData(p1 uint8) ([]byte, error) {
+	return self.getData(0xFF, p1, 0x00)
+}
+
+func (self *Reader) getData(cla uint8, p1 uint8, p2 uint8) ([]byte, error) {
+	if !self.connected() {
+		return nil, newError("NOT_CONNECTED", nil)
+	}
+	apdu := NewAPDUCase2(cla, 0xCA, p1, p2, 0x00)
+	sw1, sw2, data := self.Trans(apdu)
+	if sw1 == 0x6C {
+		apdu = NewAPDUCase2(cla, 0xCA, p1, p2, sw2)
+		sw1, sw2, data = self.Trans(apdu)
+	}
+	if sw1 != 0x90 || sw2 != 0x00 {
+		return nil, newError("GET_DATA_FAILED", self.apduError(sw1, sw2),
+			fmt.Sprintf("%02X %02X %02X", cla, p1, p2), sw1, sw2)
+	}
+	return data, nil
+}
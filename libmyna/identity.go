@@ -0,0 +1,154 @@
+// Full Identity Verification
+
+package libmyna
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"strings"
+)
+
+// VerifyFullIdentityの結果
+type FullIdentityReport struct {
+	Attrs       *TextAttrs            // 券面事項入力補助APの基本4情報
+	CertAttrs   *JPKICertificateAttrs // 署名用証明書の基本4情報
+	Certificate *x509.Certificate     // 署名用証明書
+
+	// 基本4情報のEFのダイジェスト値が署名のEFのAttrsDigestと一致した
+	AttrsDigestValid bool
+
+	// 署名のEFの署名値を券面事項入力補助APの証明書の公開鍵で検証できた
+	// 署名方式を仕様書や実カードで確認できていないため参考値で、OKの判定には使いません
+	SignatureValid bool
+
+	// 一致しなかった項目 (Name, Address, Birth, Sex)
+	Discrepancies []string
+}
+
+// ダイジェスト値が正しく、全ての項目が一致した場合にtrueを返します
+func (self *FullIdentityReport) OK() bool {
+	return self.AttrsDigestValid && len(self.Discrepancies) == 0
+}
+
+// 券面事項入力補助APの基本4情報と署名用証明書の基本4情報を1つのセッションで読み取り、照合します
+// helperPinは券面事項入力補助用PIN、signPinは署名用パスワードです
+// 一致しない項目があってもエラーにはせず、FullIdentityReport.Discrepanciesで返します
+func (self *Session) VerifyFullIdentity(signPin string, helperPin string) (*FullIdentityReport, error) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	if err := self.ensureCard(); err != nil {
+		return nil, err
+	}
+	err := self.reader.AuthenticateAP("TEXT", "0011", helperPin)
+	if err != nil {
+		return nil, err
+	}
+	textAP := TextAP{self.reader}
+	raw, err := textAP.ReadAttributesRaw()
+	if err != nil {
+		return nil, err
+	}
+	attrs, err := parseTextAttrs(raw, self.reader.attrDecoder)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := textAP.ReadSignature()
+	if err != nil {
+		return nil, err
+	}
+	textCert, err := textAP.ReadCertificate()
+	if err != nil {
+		return nil, err
+	}
+
+	jpkiAP, err := self.reader.SelectJPKIAP()
+	if err != nil {
+		return nil, err
+	}
+	err = jpkiAP.VerifySignPin(signPin)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := jpkiAP.ReadCertificate(self.reader.profile.SignCertEF)
+	if err != nil {
+		return nil, err
+	}
+	return newFullIdentityReport(attrs, raw, signature, textCert, cert)
+}
+
+func newFullIdentityReport(attrs *TextAttrs, raw []byte, signature *TextSignature,
+	textCert *TextCertificate, cert *x509.Certificate) (*FullIdentityReport, error) {
+	jpkiCert := JPKICertificate{cert}
+	certAttrs, err := jpkiCert.GetAttributes()
+	if err != nil {
+		return nil, err
+	}
+	if certAttrs == nil {
+		return nil, newError("NO_CERT_ATTRS", nil)
+	}
+	report := FullIdentityReport{
+		Attrs:            attrs,
+		CertAttrs:        certAttrs,
+		Certificate:      cert,
+		AttrsDigestValid: attrsDigestMatches(raw, signature.AttrsDigest),
+	}
+	// 証明書の形式が想定と異なる場合もSignatureValidをfalseにするだけにします
+	pubkey, err := textCert.PublicKey()
+	if err == nil {
+		report.SignatureValid = verifyTextSignature(pubkey, signature)
+	}
+	fields := []struct {
+		name       string
+		text, cert string
+	}{
+		{"Name", attrs.Name, certAttrs.Name},
+		{"Address", attrs.Address, certAttrs.Addr},
+		{"Birth", attrs.Birth, certAttrs.Birth},
+		{"Sex", attrs.Sex, certAttrs.Sex},
+	}
+	for _, field := range fields {
+		if normalizeAttr(field.text) != normalizeAttr(field.cert) {
+			report.Discrepancies = append(report.Discrepancies, field.name)
+		}
+	}
+	return &report, nil
+}
+
+// ダイジェスト値の長さからSHA-1またはSHA-256で比較します
+func attrsDigestMatches(raw []byte, digest []byte) bool {
+	switch len(digest) {
+	case sha256.Size:
+		sum := sha256.Sum256(raw)
+		return bytes.Equal(sum[:], digest)
+	case sha1.Size:
+		sum := sha1.Sum(raw)
+		return bytes.Equal(sum[:], digest)
+	default:
+		return false
+	}
+}
+
+// 署名値はマイナンバーと基本4情報のダイジェスト値を連結したものに対する
+// PKCS#1 v1.5の署名であると仮定しています
+// ハッシュ関数はダイジェスト値の長さから判定します
+func verifyTextSignature(pubkey *rsa.PublicKey, signature *TextSignature) bool {
+	hash := crypto.SHA256
+	if len(signature.AttrsDigest) == sha1.Size {
+		hash = crypto.SHA1
+	}
+	h := hash.New()
+	h.Write(signature.MyNumDigest)
+	h.Write(signature.AttrsDigest)
+	return rsa.VerifyPKCS1v15(pubkey, hash, h.Sum(nil), signature.Signature) == nil
+}
+
+// 全角・半角の空白の違いは無視して比較します
+func normalizeAttr(s string) string {
+	return strings.Join(strings.FieldsFunc(s, func(r rune) bool {
+		return r == ' ' || r == '　'
+	}), "")
+}
@@ -0,0 +1,85 @@
+package libmyna
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"testing"
+)
+
+// 券面事項入力補助APの証明書と、rawに対する署名のEFを作成します
+func newTestTextSignature(t *testing.T, raw []byte) (*TextSignature, *TextCertificate) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	modulus := key.N.Bytes()
+	pubkey := append([]byte{0x81, 0x82, 0x01, 0x00}, modulus...)
+	pubkey = append(pubkey, 0x82, 0x03, 0x01, 0x00, 0x01)
+	body := append([]byte{0x7F, 0x49, 0x82, byte(len(pubkey) >> 8), byte(len(pubkey))}, pubkey...)
+
+	mynumDigest := sha256.Sum256([]byte("123456789012"))
+	attrsDigest := sha256.Sum256(raw)
+	hashed := sha256.Sum256(append(mynumDigest[:], attrsDigest[:]...))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hashed[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := &TextSignature{
+		MyNumDigest: mynumDigest[:],
+		AttrsDigest: attrsDigest[:],
+		Signature:   sig,
+	}
+	return signature, &TextCertificate{Raw: body}
+}
+
+func TestNewFullIdentityReport(t *testing.T) {
+	cert := newTestAttrCert(t, map[int]string{
+		1: "山田　太郎", 3: "1", 4: "19800101", 5: "東京都千代田区千代田１－１"})
+	raw := []byte{0xFF, 0x20, 0x00}
+	signature, textCert := newTestTextSignature(t, raw)
+
+	attrs := &TextAttrs{Name: "山田 太郎", Address: "東京都千代田区千代田１－１",
+		Birth: "19800101", Sex: "1"}
+	report, err := newFullIdentityReport(attrs, raw, signature, textCert, cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.OK() || !report.SignatureValid {
+		t.Errorf("report should be OK: %+v", report)
+	}
+
+	forged := *signature
+	forged.Signature = append([]byte{}, signature.Signature...)
+	forged.Signature[0] ^= 0xFF
+	report, err = newFullIdentityReport(attrs, raw, &forged, textCert, cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 署名方式が未確認のため、署名値の検証結果はOKの判定に使わない
+	if report.SignatureValid || !report.OK() {
+		t.Errorf("forged signature should only clear SignatureValid: %+v", report)
+	}
+	report, err = newFullIdentityReport(attrs, raw, signature, &TextCertificate{}, cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.SignatureValid || !report.OK() {
+		t.Errorf("unexpected report for an unparsable certificate: %+v", report)
+	}
+
+	attrs.Birth = "19800102"
+	report, err = newFullIdentityReport(attrs, []byte{0xFF, 0x20, 0x01}, signature, textCert, cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.AttrsDigestValid || len(report.Discrepancies) != 1 || report.Discrepancies[0] != "Birth" {
+		t.Errorf("unexpected report: %+v", report)
+	}
+
+	plain, _ := newTestCert(t)
+	if _, err = newFullIdentityReport(attrs, raw, signature, textCert, plain); err == nil {
+		t.Error("newFullIdentityReport should fail without certificate attributes")
+	}
+}
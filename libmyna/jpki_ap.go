@@ -6,9 +6,9 @@ import (
 	"bytes"
 	"crypto/x509"
 	"crypto/x509/pkix"
-	"errors"
 	"fmt"
 	"github.com/jpki/myna/asn1"
+	"strings"
 )
 
 type JPKIAP struct {
@@ -16,7 +16,7 @@ type JPKIAP struct {
 }
 
 func (self *JPKIAP) GetToken() (string, error) {
-	err := self.reader.SelectEF("00 06") // トークン情報EF
+	err := self.reader.SelectEF(self.reader.profile.TokenEF) // トークン情報EF
 	if err != nil {
 		return "", err
 	}
@@ -26,8 +26,31 @@ func (self *JPKIAP) GetToken() (string, error) {
 	return token, nil
 }
 
+// トークン情報の末尾からJPKI APの版を判定します
+// JPKIAPICCTOKEN2は"2"、末尾に番号の無いJPKIAPICCTOKEN(住基カード)は"1"です
+// トークン情報の形式が異なる場合は空文字列を返します
+func (self *JPKIAP) GetVersion() (string, error) {
+	token, err := self.GetToken()
+	if err != nil {
+		return "", err
+	}
+	return jpkiAPVersion(token), nil
+}
+
+func jpkiAPVersion(token string) string {
+	const prefix = "JPKIAPICCTOKEN"
+	if !strings.HasPrefix(token, prefix) {
+		return ""
+	}
+	version := strings.TrimPrefix(token, prefix)
+	if version == "" {
+		return "1"
+	}
+	return version
+}
+
 func (self *JPKIAP) LookupAuthPin() (int, error) {
-	err := self.reader.SelectEF("00 18") // JPKI認証用PIN
+	err := self.reader.SelectEF(self.reader.profile.AuthPinEF) // JPKI認証用PIN
 	if err != nil {
 		return 0, err
 	}
@@ -36,7 +59,7 @@ func (self *JPKIAP) LookupAuthPin() (int, error) {
 }
 
 func (self *JPKIAP) VerifyAuthPin(pin string) error {
-	err := self.reader.SelectEF("00 18") // JPKI認証用PIN
+	err := self.reader.SelectEF(self.reader.profile.AuthPinEF) // JPKI認証用PIN
 	if err != nil {
 		return err
 	}
@@ -47,8 +70,16 @@ func (self *JPKIAP) VerifyAuthPin(pin string) error {
 	return nil
 }
 
+func (self *JPKIAP) VerifyAuthPinWithProvider(provider PinProvider) error {
+	err := self.reader.SelectEF(self.reader.profile.AuthPinEF) // JPKI認証用PIN
+	if err != nil {
+		return err
+	}
+	return self.reader.VerifyWithProvider(provider)
+}
+
 func (self *JPKIAP) LookupSignPin() (int, error) {
-	err := self.reader.SelectEF("00 1B") // JPKI署名用PIN
+	err := self.reader.SelectEF(self.reader.profile.SignPinEF) // JPKI署名用PIN
 	if err != nil {
 		return 0, err
 	}
@@ -57,7 +88,7 @@ func (self *JPKIAP) LookupSignPin() (int, error) {
 }
 
 func (self *JPKIAP) VerifySignPin(pin string) error {
-	err := self.reader.SelectEF("00 1B") // JPKI署名用PIN
+	err := self.reader.SelectEF(self.reader.profile.SignPinEF) // JPKI署名用PIN
 	if err != nil {
 		return err
 	}
@@ -68,19 +99,60 @@ func (self *JPKIAP) VerifySignPin(pin string) error {
 	return nil
 }
 
-func (self *JPKIAP) ReadCertificate(efid string) (*x509.Certificate, error) {
-	err := self.reader.SelectEF(efid)
-	data := self.reader.ReadBinary(7)
-	if len(data) != 7 {
-		return nil, errors.New("ReadBinary: invalid length")
+func (self *JPKIAP) VerifySignPinWithProvider(provider PinProvider) error {
+	err := self.reader.SelectEF(self.reader.profile.SignPinEF) // JPKI署名用PIN
+	if err != nil {
+		return err
 	}
+	return self.reader.VerifyWithProvider(provider)
+}
 
-	parser := ASN1PartialParser{}
-	err = parser.Parse(data)
+// 署名用秘密鍵でDigestInfoに署名します
+// 事前に署名用パスワードを照合しておく必要があります
+func (self *JPKIAP) SignWithSignKey(digestInfo []byte) ([]byte, error) {
+	err := self.reader.SelectEF(self.reader.profile.SignKeyEF) // Select SIGN EF
+	if err != nil {
+		return nil, err
+	}
+	return self.reader.Signature(digestInfo)
+}
+
+// 利用者証明用秘密鍵でDigestInfoに署名します
+// 事前に利用者証明用PINを照合しておく必要があります
+func (self *JPKIAP) SignWithAuthKey(digestInfo []byte) ([]byte, error) {
+	err := self.reader.SelectEF(self.reader.profile.AuthKeyEF) // Select AUTH EF
+	if err != nil {
+		return nil, err
+	}
+	return self.reader.Signature(digestInfo)
+}
+
+// 証明書のDERをカードから読み取ったまま返します
+func (self *JPKIAP) ReadCertificateRaw(efid string) ([]byte, error) {
+	return self.ReadCertificateRawWithProgress(efid, nil)
+}
+
+// 証明書の読み取りの進捗をprogressに通知します
+// totalは証明書のDER全体のバイト数です
+func (self *JPKIAP) ReadCertificateRawWithProgress(efid string, progress ProgressFunc) ([]byte, error) {
+	size, err := self.reader.selectEFSize(efid)
+	if apduErr, ok := err.(*APDUError); ok {
+		return nil, newError("CERT_EF_UNSELECTED", apduErr)
+	} else if err != nil {
+		return nil, err
+	}
+	data, err := self.reader.readBinary(size, progress)
+	if err != nil {
+		return nil, err
+	}
+	return trimTLV(data), nil
+}
+
+func (self *JPKIAP) ReadCertificate(efid string) (*x509.Certificate, error) {
+	data, err := self.ReadCertificateRaw(efid)
 	if err != nil {
 		return nil, err
 	}
-	data = self.reader.ReadBinary(parser.GetSize())
 	cert, err := x509.ParseCertificate(data)
 	if err != nil {
 		return nil, err
@@ -88,6 +160,70 @@ func (self *JPKIAP) ReadCertificate(efid string) (*x509.Certificate, error) {
 	return cert, nil
 }
 
+// efidsの証明書を順に読み取ってfnに渡します
+// 読み取りに失敗した場合やfnがエラーを返した場合はそこで中断してエラーを返します
+// 事前にJPKI APを選択し、必要なPINを照合しておいてください
+func (self *Reader) EachCertificate(efids []string, fn func(*x509.Certificate) error) error {
+	jpkiAP := JPKIAP{self}
+	for _, efid := range efids {
+		cert, err := jpkiAP.ReadCertificate(efid)
+		if err != nil {
+			return err
+		}
+		err = fn(cert)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 署名用パスワードが設定されているかを、データ無しのVERIFYで試行回数を消費せずに判定します
+// パスワードのEFが無い場合や参照データが使用できない(SW=6984)場合はfalseを返します
+// ロックされている場合は設定済みとしてtrueを返します
+func (self *JPKIAP) IsSignPinInitialized() (bool, error) {
+	err := self.reader.SelectEF(self.reader.profile.SignPinEF)
+	if apduErr, ok := err.(*APDUError); ok && apduErr.sw1 == 0x6A &&
+		(apduErr.sw2 == 0x82 || apduErr.sw2 == 0x86) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	apdu := NewAPDUCase1(0x00, 0x20, 0x00, 0x80)
+	sw1, sw2, _ := self.reader.Trans(apdu)
+	switch {
+	case sw1 == 0x63, sw1 == 0x90 && sw2 == 0x00, sw1 == 0x69 && sw2 == 0x83:
+		return true, nil
+	case sw1 == 0x69 && sw2 == 0x84:
+		return false, nil
+	default:
+		return false, self.reader.apduError(sw1, sw2)
+	}
+}
+
+// 署名用電子証明書が発行されているかをパスワードを照合せずに判定します
+// 署名用電子証明書が発行されていない(15歳未満など)カードではfalseを返します
+func (self *JPKIAP) HasSignCert() (bool, error) {
+	err := self.reader.SelectEF(self.reader.profile.SignCertEF)
+	if apduErr, ok := err.(*APDUError); ok && apduErr.sw1 == 0x6A &&
+		(apduErr.sw2 == 0x82 || apduErr.sw2 == 0x86) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	apdu := NewAPDUCase2(0x00, 0xB0, 0x00, 0x00, 1)
+	sw1, sw2, data := self.reader.Trans(apdu)
+	switch {
+	case sw1 == 0x69 && sw2 == 0x82:
+		// 読み取りにパスワードが必要 = 証明書が格納されている
+		return true, nil
+	case sw1 == 0x90 && sw2 == 0x00:
+		return len(data) == 1 && data[0] == 0x30, nil
+	default:
+		return false, self.reader.apduError(sw1, sw2)
+	}
+}
+
 type JPKICertificate struct {
 	*x509.Certificate
 }
@@ -166,6 +302,85 @@ func (self *JPKICertificate) GetAttributes() (*JPKICertificateAttrs, error) {
 	return &attrs, nil
 }
 
+// 公的個人認証の証明書の独自拡張
+//
+// 基本4情報はSubjectAltNameのotherNameに以下のOIDで格納されています
+//
+//	1.2.392.200149.8.5.5.1 氏名          (Attrs.Name)
+//	1.2.392.200149.8.5.5.2 氏名(代替)    (Attrs.NameAlt)
+//	1.2.392.200149.8.5.5.3 性別          (Attrs.Sex)
+//	1.2.392.200149.8.5.5.4 生年月日      (Attrs.Birth)
+//	1.2.392.200149.8.5.5.5 住所          (Attrs.Addr)
+//	1.2.392.200149.8.5.5.6 住所(代替)    (Attrs.AddrAlt)
+//
+// 上記以外で1.2.392.200149配下のOIDを持つ拡張は値を解釈せずPrivateに格納します
+// 公開されている証明書プロファイルで値の形式が確認できないため、呼び出し側で解釈してください
+type JPKIExtensions struct {
+	Attrs    *JPKICertificateAttrs
+	Policies []asn1.ObjectIdentifier
+
+	// 1.2.392.200149配下の拡張 (OIDの文字列 -> 値のDER)
+	Private map[string][]byte
+}
+
+var oidJPKIArc = []int{1, 2, 392, 200149}
+
+func hasOIDPrefix(oid []int, prefix []int) bool {
+	if len(oid) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if oid[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func ParseJPKIExtensions(cert *x509.Certificate) (*JPKIExtensions, error) {
+	jpkiCert := JPKICertificate{cert}
+	attrs, err := jpkiCert.GetAttributes()
+	if err != nil {
+		return nil, err
+	}
+	ext := JPKIExtensions{
+		Attrs:   attrs,
+		Private: map[string][]byte{},
+	}
+	for _, policy := range cert.PolicyIdentifiers {
+		ext.Policies = append(ext.Policies, asn1.ObjectIdentifier(policy))
+	}
+	for _, e := range cert.Extensions {
+		if hasOIDPrefix(e.Id, oidJPKIArc) {
+			ext.Private[e.Id.String()] = e.Value
+		}
+	}
+	return &ext, nil
+}
+
+// 署名の確認画面に表示するための署名用証明書の情報
+type SignCertSubject struct {
+	Name        string                // 氏名 (基本4情報が無い場合はSubjectのCN)
+	Attrs       *JPKICertificateAttrs // 基本4情報 (含まれない場合はnil)
+	Serial      string                // 16進数
+	Certificate *x509.Certificate
+}
+
+func NewSignCertSubject(cert *x509.Certificate) *SignCertSubject {
+	jpkiCert := JPKICertificate{cert}
+	attrs, _ := jpkiCert.GetAttributes()
+	subject := SignCertSubject{
+		Name:        cert.Subject.CommonName,
+		Attrs:       attrs,
+		Serial:      fmt.Sprintf("%X", cert.SerialNumber),
+		Certificate: cert,
+	}
+	if attrs != nil && attrs.Name != "" {
+		subject.Name = attrs.Name
+	}
+	return &subject
+}
+
 func (self *JPKICertificate) ToString() string {
 	var ret string
 	ret += fmt.Sprintf("SerialNumber: %s\n", self.SerialNumber)
@@ -0,0 +1,279 @@
+package libmyna
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestJPKIAPVersion(t *testing.T) {
+	tests := map[string]string{
+		"JPKIAPICCTOKEN2": "2",
+		"JPKIAPICCTOKEN":  "1",
+		"":                "",
+		"UNKNOWN":         "",
+	}
+	for token, want := range tests {
+		if got := jpkiAPVersion(token); got != want {
+			t.Errorf("jpkiAPVersion(%q) = %q, want %q", token, got, want)
+		}
+	}
+}
+
+type testOtherName struct {
+	Oid    asn1.ObjectIdentifier
+	Values struct {
+		Value string `asn1:"utf8"`
+	} `asn1:"tag:0"`
+}
+
+// SubjectAltNameのotherNameに基本4情報を格納した自己署名証明書を作成します
+// extraに指定した拡張も証明書に含めます
+func newTestAttrCert(t *testing.T, attrs map[int]string, extra ...pkix.Extension) *x509.Certificate {
+	var names []byte
+	for oid, value := range attrs {
+		var on testOtherName
+		on.Oid = asn1.ObjectIdentifier{1, 2, 392, 200149, 8, 5, 5, oid}
+		on.Values.Value = value
+		der, err := asn1.MarshalWithParams(on, "tag:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, der...)
+	}
+	san, _ := asn1.Marshal(asn1.RawValue{Tag: asn1.TagSequence, IsCompound: true, Bytes: names})
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtraExtensions: append([]pkix.Extension{
+			{Id: asn1.ObjectIdentifier{2, 5, 29, 17}, Value: san},
+		}, extra...),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestParseJPKIExtensions(t *testing.T) {
+	cert := newTestAttrCert(t, map[int]string{1: "山田太郎", 4: "19800101"},
+		pkix.Extension{Id: asn1.ObjectIdentifier{1, 2, 392, 200149, 99}, Value: []byte{0x05, 0x00}},
+		pkix.Extension{Id: asn1.ObjectIdentifier{1, 2, 392, 200149, 8, 5, 2, 1}, Value: []byte{0x04, 0x00}})
+	ext, err := ParseJPKIExtensions(cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ext.Attrs == nil || ext.Attrs.Name != "山田太郎" || ext.Attrs.Birth != "19800101" {
+		t.Errorf("unexpected attributes: %+v", ext.Attrs)
+	}
+	if _, ok := ext.Private["1.2.392.200149.99"]; !ok {
+		t.Errorf("private extension not found: %v", ext.Private)
+	}
+	// 値を解釈しないため、BOOLEAN以外の値でも証明書を拒否しない
+	if _, ok := ext.Private["1.2.392.200149.8.5.2.1"]; !ok {
+		t.Errorf("private extension not found: %v", ext.Private)
+	}
+}
+
+func TestHasSignCert(t *testing.T) {
+	tests := []struct {
+		name string
+		card mapTransport
+		want bool
+	}{
+		{"protected", mapTransport{
+			"00 A4 02 0C 02 00 01": {0x90, 0x00},
+			"00 B0 00 00 01":       {0x69, 0x82},
+		}, true},
+		{"missing", mapTransport{}, false},
+		{"empty", mapTransport{
+			"00 A4 02 0C 02 00 01": {0x90, 0x00},
+			"00 B0 00 00 01":       {0xFF, 0x90, 0x00},
+		}, false},
+	}
+	for _, test := range tests {
+		jpkiAP := JPKIAP{NewReaderWithTransport(test.card)}
+		got, err := jpkiAP.HasSignCert()
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+		}
+		if got != test.want {
+			t.Errorf("%s: HasSignCert = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestIsSignPinInitialized(t *testing.T) {
+	tests := []struct {
+		name string
+		sw   []byte
+		want bool
+	}{
+		{"set", []byte{0x63, 0xC5}, true},
+		{"blocked", []byte{0x69, 0x83}, true},
+		{"unset", []byte{0x69, 0x84}, false},
+	}
+	for _, test := range tests {
+		card := mapTransport{
+			"00 A4 02 0C 02 00 1B": {0x90, 0x00},
+			"00 20 00 80":          test.sw,
+		}
+		jpkiAP := JPKIAP{NewReaderWithTransport(card)}
+		got, err := jpkiAP.IsSignPinInitialized()
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+		}
+		if got != test.want {
+			t.Errorf("%s: IsSignPinInitialized = %v, want %v", test.name, got, test.want)
+		}
+	}
+	jpkiAP := JPKIAP{NewReaderWithTransport(mapTransport{})}
+	if got, err := jpkiAP.IsSignPinInitialized(); got || err != nil {
+		t.Errorf("missing: IsSignPinInitialized = %v, %v", got, err)
+	}
+}
+
+func TestEachCertificate(t *testing.T) {
+	cert, _ := newTestCert(t)
+	ok := []byte{0x90, 0x00}
+	card := mapTransport{
+		"00 A4 02 0C 02 00 0A": ok,
+		"00 B0 00 00 07":       append(cert.Raw[:7:7], ok...),
+	}
+	for pos := 0; pos < len(cert.Raw); pos += 0x100 {
+		end := pos + 0x100
+		if end > len(cert.Raw) {
+			end = len(cert.Raw)
+		}
+		cmd := fmt.Sprintf("00 B0 %02X %02X %02X", pos>>8, pos&0xFF, (end-pos)&0xFF)
+		card[cmd] = append(cert.Raw[pos:end:end], ok...)
+	}
+	reader := NewReaderWithTransport(card)
+
+	var count int
+	err := reader.EachCertificate([]string{"00 0A", "00 0A"}, func(c *x509.Certificate) error {
+		count++
+		if !c.Equal(cert) {
+			t.Error("unexpected certificate")
+		}
+		return nil
+	})
+	if err != nil || count != 2 {
+		t.Errorf("EachCertificate: count=%d, err=%v", count, err)
+	}
+
+	stop := errors.New("stop")
+	count = 0
+	err = reader.EachCertificate([]string{"00 0A", "00 0A"}, func(c *x509.Certificate) error {
+		count++
+		return stop
+	})
+	if err != stop || count != 1 {
+		t.Errorf("EachCertificate should stop at the first error: count=%d, err=%v", count, err)
+	}
+}
+
+func TestReadCertificatePinRequired(t *testing.T) {
+	card := mapTransport{
+		"00 A4 02 0C 02 00 01": {0x90, 0x00},
+		"00 B0 00 00 07":       {0x69, 0x82},
+		"00 B0 00 00 10":       {0x69, 0x82},
+	}
+	jpkiAP := JPKIAP{NewReaderWithTransport(card)}
+	_, err := jpkiAP.ReadCertificate(DefaultCardProfile.SignCertEF)
+	if !errors.Is(err, ErrPinRequired) {
+		t.Errorf("ReadCertificate should fail with ErrPinRequired: %v", err)
+	}
+	buf := make([]byte, 16)
+	_, err = jpkiAP.reader.ReadBinaryInto("00 01", buf)
+	if !errors.Is(err, ErrPinRequired) {
+		t.Errorf("ReadBinaryInto should fail with ErrPinRequired: %v", err)
+	}
+}
+
+func TestSignWithSignKeyChecksCert(t *testing.T) {
+	cert, key := newTestCert(t)
+	other, _ := newTestCert(t)
+	card := &efCard{key: key, efs: map[string][]byte{
+		AIDJPKIAP + "/001B": nil,
+		AIDJPKIAP + "/0001": cert.Raw,
+		AIDJPKIAP + "/001A": nil,
+	}}
+	reader := NewReaderWithTransport(card)
+	jpkiAP, err := reader.SelectJPKIAP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256.Sum256([]byte("hello myna"))
+	digestInfo, err := signerDigestInfo(hash[:], crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer := JPKISignSigner{pin: "ABC123", pubkey: cert.PublicKey, cert: cert}
+	signature, err := signer.signWithSignKey(jpkiAP, digestInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = VerifyRawSignature(cert, crypto.SHA256, hash[:], signature); err != nil {
+		t.Error(err)
+	}
+
+	signer.cert = other
+	if _, err = signer.signWithSignKey(jpkiAP, digestInfo); !errors.Is(err, ErrSignCertMismatch) {
+		t.Errorf("err = %v, want ErrSignCertMismatch", err)
+	}
+}
+
+func TestSignWithAuthKey(t *testing.T) {
+	cert, key := newTestCert(t)
+	card := &efCard{key: key, efs: map[string][]byte{
+		AIDJPKIAP + "/0018": nil,
+		AIDJPKIAP + "/0017": nil,
+	}}
+	reader := NewReaderWithTransport(card)
+	jpkiAP, err := reader.SelectJPKIAP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256.Sum256([]byte("client data"))
+	digestInfo, err := signerDigestInfo(hash[:], crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature, err := signWithAuthKey(jpkiAP, PinFromString("1234"), digestInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = rsa.VerifyPKCS1v15(cert.PublicKey.(*rsa.PublicKey), crypto.SHA256, hash[:], signature)
+	if err != nil {
+		t.Errorf("signature does not verify: %v", err)
+	}
+
+	if _, err = signerDigestInfo(hash[:20], crypto.SHA256); err == nil {
+		t.Error("signerDigestInfo should reject a short digest")
+	}
+	if _, err = signerDigestInfo(hash[:], &rsa.PSSOptions{Hash: crypto.SHA256}); err == nil {
+		t.Error("signerDigestInfo should reject PSS")
+	}
+}
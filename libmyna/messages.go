@@ -0,0 +1,233 @@
+// Error Message Catalog
+
+package libmyna
+
+import (
+	"fmt"
+	"sync"
+)
+
+// エラーコードごとのメッセージ
+var messages = map[string]map[string]string{
+	"ja": {
+		"AP_NOT_FOUND":         "このカードはマイナンバーカードではありません",
+		"MULTIPLE_READERS":     "複数のリーダーが見つかりました。使用するリーダーを指定してください",
+		"READER_NOT_FOUND":     "リーダーが見つかりません",
+		"CARD_NOT_FOUND":       "カードが見つかりません",
+		"NOT_CONNECTED":        "カードに接続していません",
+		"NOT_MYNUMBER_CARD":    "個人番号カードではありません",
+		"TOKEN_UNREADABLE":     "トークン情報を取得できません",
+		"JUKI_CARD":            "これは住基カードですね?",
+		"UNKNOWN_TOKEN":        "不明なトークン情報: %s",
+		"UNKNOWN_PIN_TYPE":     "不明なPINの種別です: %s",
+		"UNKNOWN_CERT_TYPE":    "不明な証明書の種別です: %s",
+		"INVALID_VISUAL_PIN":   "照合番号Aは12桁、照合番号Bは14桁の数字です",
+		"INVALID_PIN_FORMAT":   "暗証番号(4桁)を入力してください。",
+		"INVALID_PIN_LENGTH":   "パスワードの長さが正しくありません",
+		"INVALID_PIN_CHARS":    "パスワードの文字種が不正です",
+		"UNKNOWN_PIN_EF":       "PINの仕様が不明なEFです: %s",
+		"INVALID_MYNUMBER":     "個人番号は12桁の数字です",
+		"MYNUMBER_CHECK_DIGIT": "個人番号の検査用数字が一致しません",
+		"PIN_EMPTY":            "PINが空です",
+		"PIN_NOT_GIVEN":        "PINが指定されていません",
+		"AUTH_SELECT_AP":       "APを選択できません(%s): %s",
+		"AUTH_SELECT_PIN_EF":   "PINのEFを選択できません(%s): %s",
+		"PIN_INCORRECT":        "暗証番号が間違っています。のこり%d回",
+		"PIN_NOW_BLOCKED":      "暗証番号が間違っています。ブロックされました",
+		"PIN_BLOCKED":          "暗証番号がブロックされています。",
+		"PIN_VERIFY_FAILED":    "暗証番号が間違っています SW1=%02X SW2=%02X",
+		"PIN_CHANGE_FAILED":    "PINの変更に失敗しました",
+		"WOULD_LOCK":           "暗証番号の残り試行回数が%d回のため照合を中止しました",
+		"PIN_RETRY_UNKNOWN":    "PINの残り回数を取得できません",
+		"PIN_REQUIRED":         "読み取りにはPINの照合が必要です。先にPINを照合してください",
+		"READ_ONLY":            "読み取り専用モードのためPINの変更・署名はできません",
+		"SIGN_CANCELED":        "署名が取り消されました",
+		"SIGN_CERT_MISMATCH":   "確認した署名用証明書と署名するカードの証明書が一致しません",
+		"NO_CERT_ATTRS":        "署名用証明書に基本4情報が含まれていません",
+		"INVALID_TEXT_CERT":    "券面事項入力補助APの証明書から公開鍵を取り出せません",
+		"APDU_TIMEOUT":         "APDUの応答がタイムアウトしました。カードとリーダーの接触を確認してください",
+		"READ_BINARY_FAILED":   "READ BINARYで必要な長さを読み取れませんでした",
+		"READ_BINARY_RETRY":    "READ BINARYの応答が不正です(SW1=6C SW2=%02X)",
+		"EF_TOO_LARGE":         "EF(%s)が大きすぎるため、READ BINARYで末尾まで読み取れません",
+		"INVALID_TRACE_LINE":   "トレースの%d行目が不正です: %s",
+		"TRACE_NO_RESPONSE":    "トレースの末尾に応答がありません",
+		"TRACE_EXHAUSTED":      "記録の末尾を超えてAPDUが送信されました: % X",
+		"TRACE_MISMATCH":       "%d番目のAPDUが記録と異なります: 記録 %s, 送信 % X",
+		"PSS_UNSUPPORTED":      "カードの署名はRSA-PSSに対応していません",
+		"INVALID_DIGEST_SIZE":  "ダイジェスト値の長さ(%dバイト)が%sの長さ(%dバイト)と異なります",
+		"NON_RSA_KEY":          "RSA以外の公開鍵には対応していません",
+		"KEY_USAGE_MISSING":    "証明書の鍵用途に%sが含まれていません",
+		"NO_OCSP_RESPONDER":    "OCSPレスポンダが証明書に記載されていません",
+		"OCSP_FAILED":          "OCSPレスポンダがエラーを返しました: %s",
+		"OCSP_STALE":           "OCSPの応答が古くなっています (NextUpdate: %s)",
+		"CERT_NOT_YET_VALID":   "証明書の有効期間前です (NotBefore: %s)",
+		"CERT_EXPIRED":         "証明書の有効期限が切れています (NotAfter: %s)",
+		"CERT_CHAIN_INVALID":   "証明書チェーンを検証できません: %s",
+		"OCSP_NO_ISSUER":       "OCSP: 発行者証明書がありません",
+		"OCSP_CHECK_FAILED":    "OCSP: %s",
+		"OCSP_CERT_STATUS":     "OCSP: 証明書の状態が%sです",
+		"UNKNOWN_PROTOCOL":     "不明なプロトコルです: %s",
+		"UID_UNAVAILABLE":      "カードのUIDを取得できません。非接触のリーダーを使用してください",
+		"INVALID_DATA_TAG":     "データオブジェクトのタグは1バイトまたは2バイトで指定してください: %s",
+		"GET_DATA_FAILED":      "GET DATA(%s)に失敗しました: SW=%02X%02X",
+		"SIGNATURE_FAILED":     "署名エラー(%0X, %0X)",
+		"SELF_VERIFY_FAILED":   "作成した署名を検証できません。カードが不正な署名値を返しました",
+		"UNKNOWN_CHARSET":      "氏名・住所の文字コードがUTF-8ではありません。AttrDecoderで変換方法を指定してください",
+		"CERT_EF_UNSELECTED":   "証明書のEFを選択できません",
+		"UNSUPPORTED_DIGEST":   "サポートされていないハッシュアルゴリズムです: %s (%s)",
+		"UNSUPPORTED_FORMAT":   "サポートされていない形式です: %s",
+		"UNSUPPORTED_SID":      "この署名者の参照方法には対応していません。subjectKeyIdentifierはデタッチ署名でのみ指定できます",
+		"NO_SUBJECT_KEY_ID":    "証明書にsubjectKeyIdentifierがありません",
+		"OUTPUT_EXISTS":        "出力ファイルが既に存在します: %s",
+		"OUTPUT_COLLISION":     "%sと%sの出力先が重複しています: %s",
+		"UNKNOWN_LANGUAGE":     "サポートされていない言語です: %s",
+		"READER_NOT_SPECIFIED": "指定されたリーダーが見つかりません: %s",
+		"SIGNER_NOT_FOUND":     "署名者の証明書が1つに特定できません",
+		"ATTRIBUTE_NOT_FOUND":  "属性がありません: %s",
+		"CMS_DIGEST_MISMATCH":  "コンテンツのダイジェスト値がmessageDigest属性と一致しません",
+		"INVALID_ASSERTION":    "ログイン用アサーションの形式または署名が不正です",
+		"SIGNER_MISMATCH":      "署名者が期待した相手ではありません(%s: %s)",
+		"EMPTY_CHAIN":          "証明書チェーンが空です",
+		"INVALID_FCI":          "FCIの形式が正しくありません",
+		"INVALID_APINFO":       "APInfoの長さが不正です",
+		"INVALID_KEYID":        "KeyIDの長さが不正です",
+		"ASN1_TOO_SHORT":       "ASN.1のタグまたは長さの途中でデータが終わっています",
+		"ASN1_INVALID_TAG":     "ASN.1のタグの長さが不正です",
+		"INVALID_BUNDLE":       "署名アーカイブの形式が正しくありません: %s",
+		"NO_CRL_DP":            "署名用証明書にHTTPのCRL配布点がありません",
+		"NO_TSA_ROOTS":         "タイムスタンプを検証するにはTSA証明書の信頼点を指定してください",
+		"NOT_TIMESTAMP_TOKEN":  "タイムスタンプトークンではありません",
+		"NO_TSA_CERT":          "TSA証明書が見つかりません",
+		"TSA_CERT_USAGE":       "TSA証明書にタイムスタンプ用途が含まれていません",
+		"TIMESTAMP_MISMATCH":   "タイムスタンプの対象が署名値と一致しません",
+		"INVALID_CERT_FILE":    "証明書を読み込めません: %s",
+		"CRL_FETCH_FAILED":     "CRLを取得できません: %s",
+		"ROOTS_NOT_CONFIGURED": "信頼点のルート証明書が設定されていません。JPKIRootsを設定してください",
+		"INVALID_AID":          "AIDの長さが不正です(%dバイト)。5から16バイトで指定してください",
+		"NONCE_MISMATCH":       "ログイン用アサーションのnonceが一致しません",
+	},
+	"en": {
+		"AP_NOT_FOUND":         "this card is not a My Number Card",
+		"MULTIPLE_READERS":     "multiple readers found; specify the reader to use",
+		"READER_NOT_FOUND":     "no card reader found",
+		"CARD_NOT_FOUND":       "no card found",
+		"NOT_CONNECTED":        "not connected to a card",
+		"NOT_MYNUMBER_CARD":    "not a My Number Card",
+		"TOKEN_UNREADABLE":     "cannot read the token information",
+		"JUKI_CARD":            "this looks like a Juki card",
+		"UNKNOWN_TOKEN":        "unknown token information: %s",
+		"UNKNOWN_PIN_TYPE":     "unknown PIN type: %s",
+		"UNKNOWN_CERT_TYPE":    "unknown certificate type: %s",
+		"INVALID_VISUAL_PIN":   "verification number A must be 12 digits and B must be 14 digits",
+		"INVALID_PIN_FORMAT":   "enter the 4-digit PIN",
+		"INVALID_PIN_LENGTH":   "invalid password length",
+		"INVALID_PIN_CHARS":    "the password must consist of A-Z and 0-9",
+		"UNKNOWN_PIN_EF":       "unknown PIN specification for EF: %s",
+		"INVALID_MYNUMBER":     "the individual number must be 12 digits",
+		"MYNUMBER_CHECK_DIGIT": "the check digit of the individual number does not match",
+		"PIN_EMPTY":            "PIN is empty",
+		"PIN_NOT_GIVEN":        "no PIN was given",
+		"AUTH_SELECT_AP":       "cannot select the AP (%s): %s",
+		"AUTH_SELECT_PIN_EF":   "cannot select the PIN EF (%s): %s",
+		"PIN_INCORRECT":        "incorrect PIN, %d attempts remaining",
+		"PIN_NOW_BLOCKED":      "incorrect PIN, the PIN is now blocked",
+		"PIN_BLOCKED":          "the PIN is blocked",
+		"PIN_VERIFY_FAILED":    "PIN verification failed SW1=%02X SW2=%02X",
+		"PIN_CHANGE_FAILED":    "failed to change the PIN",
+		"WOULD_LOCK":           "verification aborted; only %d PIN attempt(s) remaining",
+		"PIN_RETRY_UNKNOWN":    "cannot get the PIN retry count",
+		"PIN_REQUIRED":         "reading requires PIN verification; verify the PIN first",
+		"READ_ONLY":            "PIN changes and signing are disabled in read-only mode",
+		"SIGN_CANCELED":        "signing was canceled",
+		"SIGN_CERT_MISMATCH":   "the card's signing certificate differs from the confirmed one",
+		"NO_CERT_ATTRS":        "the signing certificate does not contain the basic attributes",
+		"INVALID_TEXT_CERT":    "cannot extract the public key from the text AP certificate",
+		"APDU_TIMEOUT":         "the card did not respond to the APDU in time; check the card and the reader",
+		"READ_BINARY_FAILED":   "READ BINARY did not return the expected length",
+		"READ_BINARY_RETRY":    "invalid READ BINARY response (SW1=6C SW2=%02X)",
+		"EF_TOO_LARGE":         "the EF (%s) is too large to read to the end with READ BINARY",
+		"INVALID_TRACE_LINE":   "invalid trace at line %d: %s",
+		"TRACE_NO_RESPONSE":    "the trace ends without a response",
+		"TRACE_EXHAUSTED":      "an APDU was sent beyond the end of the trace: % X",
+		"TRACE_MISMATCH":       "APDU #%d differs from the trace: recorded %s, sent % X",
+		"PSS_UNSUPPORTED":      "the card does not support RSA-PSS signatures",
+		"INVALID_DIGEST_SIZE":  "digest is %d bytes but %s requires %d bytes",
+		"NON_RSA_KEY":          "only RSA public keys are supported",
+		"KEY_USAGE_MISSING":    "the certificate key usage does not include %s",
+		"NO_OCSP_RESPONDER":    "the certificate has no OCSP responder",
+		"OCSP_FAILED":          "the OCSP responder returned an error: %s",
+		"OCSP_STALE":           "the OCSP response is stale (NextUpdate: %s)",
+		"CERT_NOT_YET_VALID":   "the certificate is not yet valid (NotBefore: %s)",
+		"CERT_EXPIRED":         "the certificate has expired (NotAfter: %s)",
+		"CERT_CHAIN_INVALID":   "cannot verify the certificate chain: %s",
+		"OCSP_NO_ISSUER":       "OCSP: the issuer certificate is missing",
+		"OCSP_CHECK_FAILED":    "OCSP: %s",
+		"OCSP_CERT_STATUS":     "OCSP: the certificate status is %s",
+		"UNKNOWN_PROTOCOL":     "unknown protocol: %s",
+		"UID_UNAVAILABLE":      "cannot get the card UID; use a contactless reader",
+		"INVALID_DATA_TAG":     "data object tag must be 1 or 2 bytes: %s",
+		"GET_DATA_FAILED":      "GET DATA (%s) failed: SW=%02X%02X",
+		"SIGNATURE_FAILED":     "signing failed (%0X, %0X)",
+		"SELF_VERIFY_FAILED":   "the produced signature does not verify; the card returned a bad signature",
+		"UNKNOWN_CHARSET":      "the name or address is not UTF-8; specify a decoder with AttrDecoder",
+		"CERT_EF_UNSELECTED":   "cannot select the certificate EF",
+		"UNSUPPORTED_DIGEST":   "unsupported digest algorithm: %s (%s)",
+		"UNSUPPORTED_FORMAT":   "unsupported format: %s",
+		"UNSUPPORTED_SID":      "unsupported signer identifier; subjectKeyIdentifier is only available for detached signatures",
+		"NO_SUBJECT_KEY_ID":    "the certificate has no subjectKeyIdentifier",
+		"OUTPUT_EXISTS":        "output file already exists: %s",
+		"OUTPUT_COLLISION":     "%s and %s resolve to the same output: %s",
+		"UNKNOWN_LANGUAGE":     "unsupported language: %s",
+		"READER_NOT_SPECIFIED": "the specified reader was not found: %s",
+		"SIGNER_NOT_FOUND":     "cannot identify a single signer certificate",
+		"ATTRIBUTE_NOT_FOUND":  "attribute not found: %s",
+		"CMS_DIGEST_MISMATCH":  "the content digest does not match the messageDigest attribute",
+		"INVALID_ASSERTION":    "the login assertion is malformed or its signature is invalid",
+		"SIGNER_MISMATCH":      "the signer is not the expected party (%s: %s)",
+		"EMPTY_CHAIN":          "the certificate chain is empty",
+		"INVALID_FCI":          "invalid FCI",
+		"INVALID_APINFO":       "invalid APInfo length",
+		"INVALID_KEYID":        "invalid KeyID length",
+		"ASN1_TOO_SHORT":       "truncated ASN.1 tag or length",
+		"ASN1_INVALID_TAG":     "unexpected ASN.1 tag size",
+		"INVALID_BUNDLE":       "invalid signature bundle: %s",
+		"NO_CRL_DP":            "the signing certificate has no HTTP CRL distribution point",
+		"NO_TSA_ROOTS":         "TSA trust anchors are required to verify the timestamp",
+		"NOT_TIMESTAMP_TOKEN":  "not a timestamp token",
+		"NO_TSA_CERT":          "the TSA certificate was not found",
+		"TSA_CERT_USAGE":       "the TSA certificate does not allow timestamping",
+		"TIMESTAMP_MISMATCH":   "the timestamp does not cover the signature value",
+		"INVALID_CERT_FILE":    "cannot load certificates: %s",
+		"CRL_FETCH_FAILED":     "cannot fetch the CRL: %s",
+		"ROOTS_NOT_CONFIGURED": "no trusted root certificates are configured; set JPKIRoots",
+		"INVALID_AID":          "invalid AID length (%d bytes); it must be 5 to 16 bytes",
+		"NONCE_MISMATCH":       "the login assertion nonce does not match",
+	},
+}
+
+var languageMutex sync.RWMutex
+var language = "ja"
+
+// エラーメッセージの言語を"ja"または"en"に切り替えます
+func SetLanguage(lang string) error {
+	if _, ok := messages[lang]; !ok {
+		return newError("UNKNOWN_LANGUAGE", nil, lang)
+	}
+	languageMutex.Lock()
+	defer languageMutex.Unlock()
+	language = lang
+	return nil
+}
+
+func localizedMessage(code string) string {
+	languageMutex.RLock()
+	lang := language
+	languageMutex.RUnlock()
+	if msg, ok := messages[lang][code]; ok {
+		return msg
+	}
+	if msg, ok := messages["ja"][code]; ok {
+		return msg
+	}
+	return fmt.Sprintf("error %s", code)
+}
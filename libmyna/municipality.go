@@ -0,0 +1,150 @@
+// Issuing Municipality
+
+package libmyna
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 都道府県コード (JIS X 0401) の順に並べた都道府県名
+var prefectures = []string{
+	"北海道", "青森県", "岩手県", "宮城県", "秋田県", "山形県", "福島県",
+	"茨城県", "栃木県", "群馬県", "埼玉県", "千葉県", "東京都", "神奈川県",
+	"新潟県", "富山県", "石川県", "福井県", "山梨県", "長野県", "岐阜県",
+	"静岡県", "愛知県", "三重県", "滋賀県", "京都府", "大阪府", "兵庫県",
+	"奈良県", "和歌山県", "鳥取県", "島根県", "岡山県", "広島県", "山口県",
+	"徳島県", "香川県", "愛媛県", "高知県", "福岡県", "佐賀県", "長崎県",
+	"熊本県", "大分県", "宮崎県", "鹿児島県", "沖縄県",
+}
+
+// 5桁の地方公共団体コードに検査数字を付けた6桁のコードを返します
+func municipalityCheckDigit(code string) string {
+	sum := 0
+	for i, c := range code {
+		sum += int(c-'0') * (6 - i)
+	}
+	return fmt.Sprintf("%s%d", code, (11-sum%11)%10)
+}
+
+// 地方公共団体コード(5桁または検査数字付きの6桁)から団体名を返します
+// 表に無いコードや検査数字が一致しないコードは空文字列を返します
+func LookupMunicipality(code string) string {
+	if len(code) == 6 {
+		if municipalityCheckDigit(code[:5]) != code {
+			return ""
+		}
+		code = code[:5]
+	}
+	if len(code) != 5 {
+		return ""
+	}
+	if name, ok := municipalities[code]; ok {
+		return name
+	}
+	// 都道府県はXX000
+	var pref int
+	if _, err := fmt.Sscanf(code, "%02d000", &pref); err == nil && pref >= 1 && pref <= len(prefectures) {
+		return prefectures[pref-1]
+	}
+	return ""
+}
+
+// 住所から都道府県と市区町村の部分を取り出します
+// 郡部では町村、それ以外では市または特別区までを市区町村とみなします
+// 政令指定都市は区ではなく市までを返します
+func splitMunicipality(address string) (string, string) {
+	addr := []rune(address)
+	for _, pref := range prefectures {
+		if !strings.HasPrefix(address, pref) {
+			continue
+		}
+		rest := addr[len([]rune(pref)):]
+		// 余市郡・高市郡のように郡名に市を含む場合があるため、先に郡を探します
+		if town := districtTown(rest); town != "" {
+			return pref, town
+		}
+		// 1文字目は市川市・町田市のように名前の一部です
+		for i := 1; i < len(rest); i++ {
+			if !strings.ContainsRune("市区", rest[i]) {
+				continue
+			}
+			// 四日市市のように名前に市を含む場合
+			if i+1 < len(rest) && rest[i+1] == rest[i] {
+				i++
+			}
+			return pref, string(rest[:i+1])
+		}
+		return pref, ""
+	}
+	return "", ""
+}
+
+// 都道府県より後ろの住所が郡部であれば町村名を返します
+// 郡の後が余市町のような町村ではなく大和郡山市のような市であれば、郡部ではないので空文字列を返します
+func districtTown(rest []rune) string {
+	for i := 1; i < len(rest); i++ {
+		if rest[i] != '郡' {
+			continue
+		}
+		town := rest[i+1:]
+		// 1文字目は市貝町のように名前の一部です
+		for j := 1; j < len(town); j++ {
+			switch town[j] {
+			case '市', '区':
+				if j+1 < len(town) && strings.ContainsRune("町村", town[j+1]) {
+					continue
+				}
+				return ""
+			case '町', '村':
+				return string(town[:j+1])
+			}
+		}
+		return ""
+	}
+	return ""
+}
+
+// 住所から市区町村を判定し、地方公共団体コードと団体名を返します
+// カードに市区町村コードは格納されていないため、券面の住所から推定します
+// 判定できない場合もエラーにはせず、コードを空文字列にして判定できた部分までの名前を返します
+func municipalityFromAddress(address string) (string, string, error) {
+	pref, city := splitMunicipality(address)
+	if pref == "" {
+		return "", "", nil
+	}
+	if city != "" {
+		for code, name := range municipalities {
+			if normalizeMunicipalityName(name) == normalizeMunicipalityName(pref+city) {
+				return municipalityCheckDigit(code), name, nil
+			}
+		}
+		return "", pref + city, nil
+	}
+	// 東京都大島町のように郡に属さない町村は、表の町村名を住所から探します
+	// 三宅島三宅村のように島名が前に付く場合があるため、最も前に現れるものを選びます
+	rest := normalizeMunicipalityName(address[len(pref):])
+	found, pos := "", -1
+	for code, name := range municipalities {
+		if !strings.HasPrefix(name, pref) {
+			continue
+		}
+		i := strings.Index(rest, normalizeMunicipalityName(name[len(pref):]))
+		if i < 0 {
+			continue
+		}
+		// 同じ位置で一致した場合は長い名前を選びます
+		if found == "" || i < pos || (i == pos && len(name) > len(municipalities[found])) {
+			found, pos = code, i
+		}
+	}
+	if found == "" {
+		return "", pref, nil
+	}
+	return municipalityCheckDigit(found), municipalities[found], nil
+}
+
+// 龍ケ崎市・茅ヶ崎市のように団体名の表記が揺れやすい文字を揃えます
+func normalizeMunicipalityName(name string) string {
+	return strings.Replace(name, "ヶ", "ケ", -1)
+}
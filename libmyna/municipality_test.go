@@ -0,0 +1,60 @@
+package libmyna
+
+import (
+	"testing"
+)
+
+func TestLookupMunicipality(t *testing.T) {
+	tests := []struct {
+		code string
+		name string
+	}{
+		{"131016", "東京都千代田区"},
+		{"13101", "東京都千代田区"},
+		{"141003", "神奈川県横浜市"},
+		{"011002", "北海道札幌市"},
+		{"012025", "北海道函館市"},
+		{"473821", "沖縄県与那国町"},
+		{"130001", "東京都"},
+		{"131017", ""}, // 検査数字の誤り
+		{"99999", ""},
+		{"1310", ""},
+	}
+	for _, test := range tests {
+		if got := LookupMunicipality(test.code); got != test.name {
+			t.Errorf("LookupMunicipality(%s) = %q, want %q", test.code, got, test.name)
+		}
+	}
+}
+
+func TestMunicipalityFromAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		code    string
+		name    string
+	}{
+		{"東京都千代田区千代田１－１", "131016", "東京都千代田区"},
+		{"神奈川県横浜市中区日本大通１", "141003", "神奈川県横浜市"},
+		{"三重県四日市市諏訪町１－５", "242021", "三重県四日市市"},
+		{"長野県大町市大町３８８７", "202126", "長野県大町市"},
+		{"栃木県芳賀郡市貝町大字市塙１２８０", "093441", "栃木県市貝町"},
+		{"北海道余市郡余市町朝日町４", "014087", "北海道余市町"},
+		{"奈良県高市郡高取町大字観覚寺９９０", "294012", "奈良県高取町"},
+		{"奈良県大和郡山市北郡山町２４８", "292036", "奈良県大和郡山市"},
+		{"福島県郡山市朝日１－２３－７", "072036", "福島県郡山市"},
+		{"神奈川県茅ケ崎市茅ケ崎１－１－１", "142077", "神奈川県茅ヶ崎市"},
+		// 郡に属さない町村
+		{"東京都大島町元町１－１－１４", "133612", "東京都大島町"},
+		{"東京都三宅島三宅村阿古４９７", "133817", "東京都三宅村"},
+		// 表に無い市区町村や判定できない住所はエラーにしない
+		{"東京都架空市本町１", "", "東京都架空市"},
+		{"東京都不明", "", "東京都"},
+		{"不明", "", ""},
+	}
+	for _, test := range tests {
+		code, name, err := municipalityFromAddress(test.address)
+		if err != nil || code != test.code || name != test.name {
+			t.Errorf("municipalityFromAddress(%s) = %q, %q, %v", test.address, code, name, err)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+// Offline Verification Bundle
+
+package libmyna
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yu-ichiro/pkcs7"
+)
+
+// オフライン検証用アーカイブのmanifest.json
+type VerificationManifest struct {
+	SignerSerial  string    `json:"signer_serial"` // 署名用証明書のシリアル番号(16進数)
+	CACerts       []string  `json:"ca_certs"`      // 格納したCA証明書のファイル名
+	CRLURL        string    `json:"crl_url"`       // CRLの取得元
+	CRLThisUpdate time.Time `json:"crl_this_update"`
+	CRLNextUpdate time.Time `json:"crl_next_update"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type zipFile struct {
+	name string
+	data []byte
+}
+
+// CRLの取得に使うHTTPクライアント
+var crlHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// 署名(DERまたはPEM)と、オフラインで検証するための証明書とCRLをzipにまとめます
+// CA証明書は署名に含まれていればそれを使い、無ければカードの署名用CA証明書を読み取ります
+// CRLは署名用証明書のCRL配布点(http/https)から一度だけ取得します
+func ExportVerificationBundle(signed []byte, out string) error {
+	if block, _ := pem.Decode(signed); block != nil {
+		signed = block.Bytes
+	}
+	p7, err := pkcs7.Parse(signed)
+	if err != nil {
+		return err
+	}
+	signer := p7.GetOnlySigner()
+	if signer == nil {
+		return newError("SIGNER_NOT_FOUND", nil)
+	}
+	chain, err := verificationChain(signer, p7.Certificates)
+	if err != nil {
+		return err
+	}
+	url, crlDER, crl, err := fetchCRL(signer, chain)
+	if err != nil {
+		return err
+	}
+
+	manifest := VerificationManifest{
+		SignerSerial:  fmt.Sprintf("%X", signer.SerialNumber),
+		CRLURL:        url,
+		CRLThisUpdate: crl.TBSCertList.ThisUpdate.UTC(),
+		CRLNextUpdate: crl.TBSCertList.NextUpdate.UTC(),
+		CreatedAt:     time.Now().UTC(),
+	}
+	files := []zipFile{
+		{"signature.p7s", signed},
+		{"signer.cer", signer.Raw},
+		{"signer.crl", crlDER},
+	}
+	for i, ca := range chain {
+		name := fmt.Sprintf("ca-%d.cer", i+1)
+		manifest.CACerts = append(manifest.CACerts, name)
+		files = append(files, zipFile{name, ca.Raw})
+	}
+	data, err := json.MarshalIndent(&manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(out, 0644, func(w io.Writer) error {
+		zw := zip.NewWriter(w)
+		for _, f := range files {
+			entry, err := zw.Create(f.name)
+			if err != nil {
+				return err
+			}
+			if _, err = entry.Write(f.data); err != nil {
+				return err
+			}
+		}
+		entry, err := zw.Create(bundleManifestName)
+		if err != nil {
+			return err
+		}
+		if _, err = entry.Write(data); err != nil {
+			return err
+		}
+		return zw.Close()
+	})
+}
+
+// 署名用証明書から自己署名の証明書までのCA証明書を集めます
+func verificationChain(signer *x509.Certificate, certs []*x509.Certificate) ([]*x509.Certificate, error) {
+	var chain []*x509.Certificate
+	cert := signer
+	for !bytes.Equal(cert.RawIssuer, cert.RawSubject) && len(chain) < len(certs) {
+		var issuer *x509.Certificate
+		for _, c := range certs {
+			if bytes.Equal(c.RawSubject, cert.RawIssuer) && cert.CheckSignatureFrom(c) == nil {
+				issuer = c
+				break
+			}
+		}
+		if issuer == nil {
+			break
+		}
+		chain = append(chain, issuer)
+		cert = issuer
+	}
+	if len(chain) > 0 || bytes.Equal(signer.RawIssuer, signer.RawSubject) {
+		return chain, nil
+	}
+	ca, err := GetJPKISignCACert()
+	if err != nil {
+		return nil, err
+	}
+	return []*x509.Certificate{ca}, nil
+}
+
+// CRL配布点からCRLを取得し、発行者のCA証明書で署名を検証します
+func fetchCRL(signer *x509.Certificate, chain []*x509.Certificate) (string, []byte, *pkix.CertificateList, error) {
+	issuer := signer
+	if len(chain) > 0 {
+		issuer = chain[0]
+	}
+	var lastErr error
+	for _, url := range signer.CRLDistributionPoints {
+		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+			continue
+		}
+		der, err := downloadCRL(url)
+		if err != nil {
+			lastErr = newError("CRL_FETCH_FAILED", err, url)
+			continue
+		}
+		crl, err := x509.ParseCRL(der)
+		if err != nil {
+			lastErr = newError("CRL_FETCH_FAILED", err, url)
+			continue
+		}
+		if err = issuer.CheckCRLSignature(crl); err != nil {
+			lastErr = newError("CRL_FETCH_FAILED", err, url)
+			continue
+		}
+		return url, der, crl, nil
+	}
+	if lastErr != nil {
+		return "", nil, nil, lastErr
+	}
+	return "", nil, nil, newError("NO_CRL_DP", nil)
+}
+
+func downloadCRL(url string) ([]byte, error) {
+	res, err := crlHTTPClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", res.StatusCode)
+	}
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	// PEM形式で配布されている場合
+	if block, _ := pem.Decode(data); block != nil {
+		data = block.Bytes
+	}
+	return data, nil
+}
@@ -0,0 +1,117 @@
+package libmyna
+
+import (
+	"archive/zip"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yu-ichiro/pkcs7"
+)
+
+func TestExportVerificationBundle(t *testing.T) {
+	caKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &caTemplate, &caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	crl, err := ca.CreateCRL(rand.Reader, caKey, nil, time.Now(), time.Now().Add(24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write(crl)
+	}))
+	defer server.Close()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(2),
+		Subject:               pkix.Name{CommonName: "test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageContentCommitment,
+		CRLDistributionPoints: []string{"ldap://example.invalid/crl", server.URL + "/sign.crl"},
+	}
+	der, err = x509.CreateCertificate(rand.Reader, &template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := crypto.SHA256.New()
+	h.Write([]byte("hello myna"))
+	signed, err := buildDetachedCms(cert, []*x509.Certificate{ca}, key, crypto.SHA256,
+		pkcs7.OIDDigestAlgorithmSHA256, h.Sum(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "myna")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	out := filepath.Join(dir, "verify.zip")
+	if err = ExportVerificationBundle(signed, out); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 {
+		t.Errorf("CRL fetched %d times", requests)
+	}
+	zr, err := zip.OpenReader(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+	want := "ca-1.cer manifest.json signature.p7s signer.cer signer.crl"
+	if got := strings.Join(names, " "); got != want {
+		t.Errorf("bundle entries = %s, want %s", got, want)
+	}
+
+	// CRL配布点が無い場合
+	cert.CRLDistributionPoints = nil
+	if _, _, _, err = fetchCRL(cert, []*x509.Certificate{ca}); err == nil {
+		t.Error("fetchCRL should fail without a distribution point")
+	}
+}
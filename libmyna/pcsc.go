@@ -0,0 +1,13 @@
+//go:build !windows
+// +build !windows
+
+package libmyna
+
+import (
+	"github.com/ebfe/scard"
+)
+
+// PC/SCライブラリのバージョン
+func PCSCVersion() string {
+	return "pcsc-lite " + scard.Version()
+}
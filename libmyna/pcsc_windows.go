@@ -0,0 +1,10 @@
+//go:build windows
+// +build windows
+
+package libmyna
+
+// PC/SCライブラリのバージョン
+// WinSCardはバージョンを公開していません
+func PCSCVersion() string {
+	return "WinSCard"
+}
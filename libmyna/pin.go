@@ -0,0 +1,56 @@
+package libmyna
+
+import (
+	"io"
+)
+
+// PINを必要になった時点で取得するための関数
+// 返されたバイト列は使用後にゼロクリアされます
+type PinProvider func() ([]byte, error)
+
+// 文字列のPINを返すPinProvider
+// 文字列自体はゼロクリアできないため、可能であればバイト列を返す関数を使ってください
+func PinFromString(pin string) PinProvider {
+	return func() ([]byte, error) {
+		return []byte(pin), nil
+	}
+}
+
+// io.Readerから1行読み取りPINとするPinProvider
+func PinFromReader(r io.Reader) PinProvider {
+	return func() ([]byte, error) {
+		var pin []byte
+		buf := make([]byte, 1)
+		for {
+			n, err := r.Read(buf)
+			if n == 1 {
+				if buf[0] == '\n' {
+					break
+				}
+				pin = append(pin, buf[0])
+			}
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				zeroBytes(pin)
+				return nil, err
+			}
+		}
+		zeroBytes(buf)
+		if len(pin) > 0 && pin[len(pin)-1] == '\r' {
+			pin[len(pin)-1] = 0
+			pin = pin[:len(pin)-1]
+		}
+		if len(pin) == 0 {
+			return nil, newError("PIN_EMPTY", nil)
+		}
+		return pin, nil
+	}
+}
+
+func zeroBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,85 @@
+package libmyna
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPinFromReader(t *testing.T) {
+	r := strings.NewReader("ABC123\r\nrest\n")
+	pin, err := PinFromReader(r)()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(pin) != "ABC123" {
+		t.Errorf("unexpected pin: %q", pin)
+	}
+	pin, err = PinFromReader(r)()
+	if err != nil || string(pin) != "rest" {
+		t.Errorf("unexpected second pin: %q %v", pin, err)
+	}
+	if _, err = PinFromReader(r)(); err == nil {
+		t.Error("empty input should fail")
+	}
+}
+
+func TestSafeVerify(t *testing.T) {
+	card := mapTransport{
+		"00 A4 04 0C 0A D3 92 F0 00 26 01 00 00 00 01": {0x90, 0x00},
+		"00 A4 02 0C 02 00 18":                         {0x90, 0x00},
+		"00 20 00 80":                                  {0x63, 0xC1},
+	}
+	reader := NewReaderWithTransport(card)
+	err := verifyReaderPin(reader, "JPKI_AUTH", "1234", true)
+	if !errors.Is(err, ErrWouldLock) {
+		t.Errorf("SafeVerify should fail with ErrWouldLock: %v", err)
+	}
+
+	card["00 20 00 80"] = []byte{0x63, 0xC3}
+	card["00 20 00 80 04 31 32 33 34"] = []byte{0x90, 0x00}
+	if err = verifyReaderPin(reader, "JPKI_AUTH", "1234", true); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestChangeAllPinsValidation(t *testing.T) {
+	_, err := ChangeAllPins(
+		map[string]string{"CARD_INPUT_HELPER": "1234", "JPKI_SIGN": "ABC123"},
+		map[string]string{"CARD_INPUT_HELPER": "5678", "JPKI_SIGN": "ABC-123"})
+	if err == nil {
+		t.Error("ChangeAllPins should reject an invalid sign password")
+	}
+	_, err = ChangeAllPins(nil, map[string]string{"UNKNOWN": "1234"})
+	if err == nil {
+		t.Error("ChangeAllPins should reject an unknown PIN type")
+	}
+}
+
+func TestIsSafeRetryCount(t *testing.T) {
+	for count, safe := range map[int]bool{0: false, 1: false, 2: true, 5: true} {
+		if isSafeRetryCount(count) != safe {
+			t.Errorf("isSafeRetryCount(%d) = %v", count, !safe)
+		}
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	ReadOnly = true
+	defer func() { ReadOnly = false }()
+
+	// カードにコマンドを送らずにエラーになる
+	reader := NewReaderWithTransport(mapTransport{})
+	if _, err := reader.Signature([]byte{0x01}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Signature = %v, want ErrReadOnly", err)
+	}
+	if reader.ChangePin("1234") {
+		t.Error("ChangePin should fail in read-only mode")
+	}
+	if err := Change4DigitPin("1234", "5678", "JPKI_AUTH"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Change4DigitPin = %v, want ErrReadOnly", err)
+	}
+	if err := ChangeJPKISignPin("ABC123", "DEF456"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("ChangeJPKISignPin = %v, want ErrReadOnly", err)
+	}
+}
@@ -0,0 +1,183 @@
+package libmyna
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ebfe/scard"
+)
+
+type ProbeReport struct {
+	PCSCVersion string          `json:"pcsc_version"`
+	Readers     []string        `json:"readers"`
+	Reader      string          `json:"reader"`
+	ATR         string          `json:"atr"`
+	Protocol    string          `json:"protocol"`
+	Token       string          `json:"token"`
+	APVersion   string          `json:"ap_version"`
+	APs         map[string]bool `json:"aps"`
+}
+
+func ProtocolString(proto scard.Protocol) string {
+	switch proto {
+	case scard.ProtocolT0:
+		return "T=0"
+	case scard.ProtocolT1:
+		return "T=1"
+	case scard.ProtocolUndefined:
+		return "undefined"
+	default:
+		return fmt.Sprintf("0x%X", uint32(proto))
+	}
+}
+
+// "T0"(または"T=0")、"T1"(または"T=1")、"any"をプロトコルに変換します
+func ParseProtocol(s string) (scard.Protocol, error) {
+	switch strings.ToUpper(s) {
+	case "T0", "T=0":
+		return scard.ProtocolT0, nil
+	case "T1", "T=1":
+		return scard.ProtocolT1, nil
+	case "", "ANY":
+		return scard.ProtocolAny, nil
+	default:
+		return scard.ProtocolUndefined, newError("UNKNOWN_PROTOCOL", nil, s)
+	}
+}
+
+// リーダーとカードの情報を収集します
+// カードの接続後に失敗した項目は空のまま報告します
+func Probe() (*ProbeReport, error) {
+	report := ProbeReport{
+		PCSCVersion: PCSCVersion(),
+		APs:         map[string]bool{},
+	}
+	reader, err := NewReader(OptionDebug)
+	if err != nil {
+		return &report, err
+	}
+	defer reader.Finalize()
+	report.Readers, _ = reader.ctx.ListReaders()
+	report.Reader = reader.name
+
+	err = reader.Connect()
+	if err != nil {
+		return &report, err
+	}
+
+	status, err := reader.Status()
+	if err == nil {
+		report.ATR = fmt.Sprintf("% X", status.Atr)
+		report.Protocol = ProtocolString(status.ActiveProtocol)
+	}
+
+	_, err = reader.SelectVisualAP()
+	report.APs["visual"] = err == nil
+	_, err = reader.SelectTextAP()
+	report.APs["text"] = err == nil
+	jpkiAP, err := reader.SelectJPKIAP()
+	report.APs["jpki"] = err == nil
+	if err == nil {
+		report.Token, _ = jpkiAP.GetToken()
+		report.APVersion = jpkiAPVersion(report.Token)
+	}
+	return &report, nil
+}
+
+// Diagnoseで最初に失敗した段階
+type DiagnoseStage int
+
+const (
+	DiagnoseOK              DiagnoseStage = iota
+	DiagnosePCSCUnavailable               // PC/SCサービス(pcscd)に接続できない
+	DiagnoseNoReader                      // リーダーが接続されていない
+	DiagnoseNoCard                        // カードが挿入されていない
+	DiagnoseNotMyNumberCard               // マイナンバーカードではない
+)
+
+func (self DiagnoseStage) String() string {
+	switch self {
+	case DiagnoseOK:
+		return "マイナンバーカードを読み取れます"
+	case DiagnosePCSCUnavailable:
+		return "PC/SCサービスに接続できません。pcscdが起動しているか確認してください"
+	case DiagnoseNoReader:
+		return "リーダーが見つかりません。リーダーの接続とドライバを確認してください"
+	case DiagnoseNoCard:
+		return "カードが見つかりません。カードをリーダーに挿入してください"
+	case DiagnoseNotMyNumberCard:
+		return "マイナンバーカードではありません"
+	default:
+		return fmt.Sprintf("DiagnoseStage(%d)", int(self))
+	}
+}
+
+type DiagnoseResult struct {
+	Stage    DiagnoseStage
+	Readers  []string
+	Reader   string   // カードが挿入されていたリーダー
+	CardType CardType // Stageがカードの判定まで進んだ場合のみ
+	Err      error    // Stageの原因となったエラー (無い場合はnil)
+}
+
+// PC/SCサービス、リーダー、カードの有無、カードの種別を順に確認します
+// 接続できない原因を段階ごとに切り分けるためのもので、カードを待機しません
+func Diagnose() DiagnoseResult {
+	result := DiagnoseResult{}
+	ctx, err := scard.EstablishContext()
+	if err != nil {
+		result.Stage = DiagnosePCSCUnavailable
+		result.Err = err
+		return result
+	}
+	defer ctx.Release()
+
+	result.Readers, err = ctx.ListReaders()
+	if err != nil && err != scard.ErrNoReadersAvailable {
+		result.Stage = DiagnosePCSCUnavailable
+		result.Err = err
+		return result
+	}
+	if len(result.Readers) == 0 {
+		result.Stage = DiagnoseNoReader
+		result.Err = err
+		return result
+	}
+
+	rs := make([]scard.ReaderState, len(result.Readers))
+	for i, name := range result.Readers {
+		rs[i].Reader = name
+		rs[i].CurrentState = scard.StateUnaware
+	}
+	err = ctx.GetStatusChange(rs, 0)
+	if err != nil {
+		result.Stage = DiagnoseNoCard
+		result.Err = err
+		return result
+	}
+	for _, state := range rs {
+		if state.EventState&scard.StatePresent != 0 {
+			result.Reader = state.Reader
+			break
+		}
+	}
+	if result.Reader == "" {
+		result.Stage = DiagnoseNoCard
+		return result
+	}
+
+	reader := NewReaderWithContext(ctx, result.Reader)
+	defer reader.Finalize()
+	err = reader.Connect()
+	if err != nil {
+		result.Stage = DiagnoseNoCard
+		result.Err = err
+		return result
+	}
+	result.CardType, err = reader.IdentifyCard()
+	if err != nil || result.CardType != CardTypeMyNumber {
+		result.Stage = DiagnoseNotMyNumberCard
+		result.Err = err
+	}
+	return result
+}
@@ -0,0 +1,27 @@
+package libmyna
+
+import (
+	"testing"
+
+	"github.com/ebfe/scard"
+)
+
+func TestParseProtocol(t *testing.T) {
+	tests := map[string]scard.Protocol{
+		"T0":  scard.ProtocolT0,
+		"t=1": scard.ProtocolT1,
+		"any": scard.ProtocolAny,
+	}
+	for s, expected := range tests {
+		proto, err := ParseProtocol(s)
+		if err != nil {
+			t.Error(err)
+		}
+		if proto != expected {
+			t.Errorf("ParseProtocol(%q) = %s", s, ProtocolString(proto))
+		}
+	}
+	if _, err := ParseProtocol("T2"); err == nil {
+		t.Error("ParseProtocol should fail: T2")
+	}
+}
@@ -0,0 +1,65 @@
+// Card Profile
+
+package libmyna
+
+import (
+	"strings"
+)
+
+// JPKI APのEF識別子の組と、既知のEFのサイズ
+// カードの世代によってEFの配置が変わった場合に差し替えられるようにしています
+type CardProfile struct {
+	TokenEF      string // トークン情報
+	AuthCertEF   string // 利用者証明用証明書
+	AuthCACertEF string // 利用者証明用CA証明書
+	AuthKeyEF    string // 利用者証明用秘密鍵
+	AuthPinEF    string // 利用者証明用PIN
+	SignCertEF   string // 署名用証明書
+	SignCACertEF string // 署名用CA証明書
+	SignKeyEF    string // 署名用秘密鍵
+	SignPinEF    string // 署名用PIN
+
+	// 券面事項入力補助APの基本4情報のEFを読み取るバイト数
+	// 0の場合は先頭を読み取って長さを判定します
+	AttrsSize uint16
+}
+
+// 現行の個人番号カードのプロファイル
+// 高水準APIはこのプロファイルを使います
+var DefaultCardProfile = CardProfile{
+	TokenEF:      "00 06",
+	AuthCertEF:   "00 0A",
+	AuthCACertEF: "00 0B",
+	AuthKeyEF:    "00 17",
+	AuthPinEF:    "00 18",
+	SignCertEF:   "00 01",
+	SignCACertEF: "00 02",
+	SignKeyEF:    "00 1A",
+	SignPinEF:    "00 1B",
+}
+
+func Profile(profile CardProfile) func(*Reader) {
+	return func(r *Reader) {
+		r.profile = profile
+	}
+}
+
+// 証明書の種別(auth・authca・sign・signca)から証明書のEFを返します
+func (self CardProfile) CertEF(certType string) (string, error) {
+	switch strings.ToUpper(certType) {
+	case "AUTH":
+		return self.AuthCertEF, nil
+	case "AUTHCA":
+		return self.AuthCACertEF, nil
+	case "SIGN":
+		return self.SignCertEF, nil
+	case "SIGNCA":
+		return self.SignCACertEF, nil
+	default:
+		return "", newError("UNKNOWN_CERT_TYPE", nil, certType)
+	}
+}
+
+func (self *Reader) Profile() CardProfile {
+	return self.profile
+}
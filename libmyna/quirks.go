@@ -0,0 +1,125 @@
+// Card/Reader Quirks
+
+package libmyna
+
+import (
+	"bytes"
+)
+
+// カードとリーダーの組み合わせごとに必要な回避策
+type ReaderQuirks struct {
+	ReadChunkSize uint16 // READ BINARYで一度に読み取るバイト数 (0の場合は256)
+	GetResponse   bool   // SW1=61の応答に対してGET RESPONSEで残りを取得する
+
+	// 拡張長のAPDUでREAD BINARYを行う
+	// ATRのカード機能に拡張長の対応が示されている場合も有効にします
+	ExtendedLength bool
+}
+
+type atrQuirk struct {
+	prefix []byte
+	quirks ReaderQuirks
+}
+
+// ATRの前方一致で回避策を選択します。先に登録されたものが優先されます
+// 動作を確認できたATRが無いため、ライブラリの既定の登録はありません
+// 表はRegisterATRQuirksで登録した場合のみ使われます
+var atrQuirks []atrQuirk
+
+// ATRが指定したプレフィックスで始まるカードに回避策を適用します
+// 接続前に呼び出してください。登録しない場合も、ATRのカード機能による拡張長の判定と
+// READ BINARYに6700が返された場合の読み取り長の縮小は自動で行います
+func RegisterATRQuirks(prefix []byte, quirks ReaderQuirks) {
+	atrQuirks = append(atrQuirks, atrQuirk{prefix, quirks})
+}
+
+func lookupATRQuirks(atr []byte) ReaderQuirks {
+	for _, q := range atrQuirks {
+		if bytes.HasPrefix(atr, q.prefix) {
+			return q.quirks
+		}
+	}
+	return ReaderQuirks{}
+}
+
+// 6700(長さが不正)を返すリーダーで再試行する際のREAD BINARYの長さ
+const fallbackReadChunkSize = 0x80
+
+// requestedバイトのREAD BINARYに6700が返された場合に読み取る長さを縮めます
+// 以降の読み取りでも縮めた長さを使います。これ以上縮められない場合はfalseを返します
+func (self *ReaderQuirks) shrinkReadChunk(requested int) bool {
+	if requested <= fallbackReadChunkSize {
+		return false
+	}
+	self.ReadChunkSize = fallbackReadChunkSize
+	return true
+}
+
+// 拡張長のREAD BINARYで一度に読み取るバイト数
+const extendedReadChunkSize = 0x1000
+
+// ATRのヒストリカルバイトを取り出します
+func atrHistoricalBytes(atr []byte) []byte {
+	if len(atr) < 2 {
+		return nil
+	}
+	k := int(atr[1] & 0x0F)
+	y := atr[1] >> 4
+	i := 2
+	for {
+		// TAi, TBi, TCiの有無
+		for _, bit := range []byte{0x1, 0x2, 0x4} {
+			if y&bit != 0 {
+				i++
+			}
+		}
+		if y&0x8 == 0 || i >= len(atr) {
+			break
+		}
+		y = atr[i] >> 4
+		i++
+	}
+	if i+k > len(atr) {
+		return nil
+	}
+	return atr[i : i+k]
+}
+
+// ヒストリカルバイトのカード機能(タグ7)に拡張長のLc/Leの対応が示されているか判定します
+func atrSupportsExtendedLength(atr []byte) bool {
+	hist := atrHistoricalBytes(atr)
+	if len(hist) == 0 {
+		return false
+	}
+	var tlv []byte
+	switch hist[0] {
+	case 0x80:
+		tlv = hist[1:]
+	case 0x00:
+		// 末尾の3バイトは状態表示
+		if len(hist) < 4 {
+			return false
+		}
+		tlv = hist[1 : len(hist)-3]
+	default:
+		return false
+	}
+	for len(tlv) > 0 {
+		tag, l := tlv[0]>>4, int(tlv[0]&0x0F)
+		if 1+l > len(tlv) {
+			return false
+		}
+		if tag == 0x7 && l >= 3 {
+			return tlv[3]&0x40 != 0
+		}
+		tlv = tlv[1+l:]
+	}
+	return false
+}
+
+func (self *ReaderQuirks) readChunkSize() uint16 {
+	if self.ReadChunkSize == 0 || self.ReadChunkSize > 0x100 {
+		return 0x100
+	}
+	return self.ReadChunkSize
+}
@@ -0,0 +1,39 @@
+package libmyna
+
+import (
+	"testing"
+)
+
+func TestLookupATRQuirks(t *testing.T) {
+	saved := atrQuirks
+	defer func() { atrQuirks = saved }()
+
+	RegisterATRQuirks([]byte{0x3B, 0xE0}, ReaderQuirks{ReadChunkSize: 0x80})
+	quirks := lookupATRQuirks([]byte{0x3B, 0xE0, 0x00, 0xFF})
+	if quirks.readChunkSize() != 0x80 {
+		t.Errorf("readChunkSize = %d, want %d", quirks.readChunkSize(), 0x80)
+	}
+	quirks = lookupATRQuirks([]byte{0x3B, 0x8F})
+	if quirks.readChunkSize() != 0x100 {
+		t.Errorf("readChunkSize = %d, want %d", quirks.readChunkSize(), 0x100)
+	}
+}
+
+func TestATRSupportsExtendedLength(t *testing.T) {
+	tests := []struct {
+		atr      string
+		extended bool
+	}{
+		{"3B 85 80 01 80 73 00 00 40 00", true},
+		{"3B 85 80 01 80 73 00 00 00 00", false},
+		{"3B 88 80 01 00 73 00 00 40 00 90 00 00", true},
+		{"3B 80 80 01 01", false},
+		{"3B 8F", false},
+	}
+	for _, test := range tests {
+		got := atrSupportsExtendedLength(ToBytes(test.atr))
+		if got != test.extended {
+			t.Errorf("atrSupportsExtendedLength(%s) = %v, want %v", test.atr, got, test.extended)
+		}
+	}
+}
@@ -1,8 +1,10 @@
 package libmyna
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
@@ -11,10 +13,31 @@ import (
 )
 
 type Reader struct {
-	ctx   *scard.Context
-	name  string
-	card  *scard.Card
-	debug bool
+	ctx     *scard.Context
+	ownsCtx bool
+	name    string
+	card    *scard.Card
+	debug   bool
+	profile CardProfile
+	atr     []byte
+	quirks  ReaderQuirks
+
+	protocol       scard.Protocol // 接続時に要求するプロトコル (ゼロ値はProtocolAny)
+	activeProtocol scard.Protocol
+
+	transport Transport
+	trace     io.Writer
+
+	attrDecoder func([]byte) (string, error) // AttrDecoderで指定した文字コードの変換
+
+	cancelCtx context.Context // CancelContextで指定した操作の期限
+
+	apduMiddleware     func([]byte) []byte // 送信前のコマンドの変換
+	responseMiddleware func([]byte) []byte // 受信後の応答の変換
+
+	apduTimeout time.Duration // 0の場合は待ち続けます
+	timeoutErr  error         // 直前のAPDUがタイムアウトした場合のエラー
+	stalled     chan struct{} // タイムアウトしたTransportのTransmitが戻ると閉じられます
 }
 
 func Debug(d bool) func(*Reader) {
@@ -25,7 +48,67 @@ func Debug(d bool) func(*Reader) {
 
 var OptionDebug = Debug(false)
 
+// 接続時に使用するプロトコルを指定します
+func Protocol(proto scard.Protocol) func(*Reader) {
+	return func(r *Reader) {
+		r.protocol = proto
+	}
+}
+
+// カードの待機とAPDUの送信をctxのキャンセルや期限で中断させます
+// 送信済みのAPDUの応答待ちは中断されず、次のAPDUの送信前にctx.Err()を返します
+func CancelContext(ctx context.Context) func(*Reader) {
+	return func(r *Reader) {
+		r.cancelCtx = ctx
+	}
+}
+
+func (self *Reader) context() context.Context {
+	if self.cancelCtx == nil {
+		return context.Background()
+	}
+	return self.cancelCtx
+}
+
+// ctxがキャンセルされたらPC/SCの待機を中断させます
+// 戻り値の関数を呼び出すと監視を終了します
+func (self *Reader) cancelOnDone(ctx context.Context) func() {
+	if ctx.Done() == nil {
+		return func() {}
+	}
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			self.ctx.Cancel()
+		case <-done:
+		}
+	}()
+	return func() { close(done) }
+}
+
+// ReaderNameを指定しなかった場合に、使用するリーダーの名前を読み取る環境変数
+const ReaderEnv = "MYNA_READER"
+
+// 使用するリーダーの名前を指定します
+// 指定しない場合は環境変数MYNA_READER、それも無い場合は最初のリーダーを使います
+func ReaderName(name string) func(*Reader) {
+	return func(r *Reader) {
+		r.name = name
+	}
+}
+
 func NewReader(opts ...func(*Reader)) (*Reader, error) {
+	return newReader(false, opts...)
+}
+
+// 複数のリーダーが接続されている場合にReaderNameまたはMYNA_READERで指定されていなければ
+// 最初のリーダーを使わずにErrMultipleReadersを返します
+func NewReaderStrict(opts ...func(*Reader)) (*Reader, error) {
+	return newReader(true, opts...)
+}
+
+func newReader(strict bool, opts ...func(*Reader)) (*Reader, error) {
 	ctx, err := scard.EstablishContext()
 	if err != nil {
 		return nil, err
@@ -33,87 +116,372 @@ func NewReader(opts ...func(*Reader)) (*Reader, error) {
 
 	readers, err := ctx.ListReaders()
 	if err != nil {
+		ctx.Release()
 		return nil, err
 	}
 
 	if len(readers) == 0 {
-		return nil, fmt.Errorf("リーダーが見つかりません")
+		ctx.Release()
+		return nil, newError("READER_NOT_FOUND", nil)
+	}
+
+	reader := new(Reader)
+	reader.ctx = ctx
+	reader.ownsCtx = true
+	reader.card = nil
+	reader.profile = DefaultCardProfile
+	for _, opt := range opts {
+		opt(reader)
+	}
+	if reader.name == "" {
+		reader.name = os.Getenv(ReaderEnv)
+	}
+
+	if reader.name != "" {
+		for _, name := range readers {
+			if name == reader.name {
+				if reader.debug {
+					fmt.Fprintf(os.Stderr, "# Reader: %s\n", reader.name)
+				}
+				return reader, nil
+			}
+		}
+		ctx.Release()
+		return nil, newError("READER_NOT_SPECIFIED", nil, reader.name)
 	}
 
 	if len(readers) >= 2 {
+		if strict {
+			ctx.Release()
+			return nil, ErrMultipleReaders
+		}
 		fmt.Fprintf(os.Stderr,
 			"警告: 複数のリーダーが見つかりました。最初のものを使います\n")
 	}
+	reader.name = readers[0]
+	if reader.debug {
+		fmt.Fprintf(os.Stderr, "# Reader: %s\n", reader.name)
+	}
+	return reader, nil
+}
 
+// 呼び出し側で確立したコンテキストを使うReaderを作成します
+// コンテキストはFinalize()で解放されないため呼び出し側で解放してください
+func NewReaderWithContext(ctx *scard.Context, name string, opts ...func(*Reader)) *Reader {
 	reader := new(Reader)
 	reader.ctx = ctx
-	reader.name = readers[0]
+	reader.ownsCtx = false
+	reader.name = name
 	reader.card = nil
+	reader.profile = DefaultCardProfile
 	for _, opt := range opts {
 		opt(reader)
 	}
-	return reader, nil
+	return reader
 }
 
 func (self *Reader) SetDebug(debug bool) {
 	self.debug = debug
 }
 
+// 次回の接続から使用するプロトコル(T=0/T=1)を指定します
+func (self *Reader) SetProtocol(proto scard.Protocol) {
+	self.protocol = proto
+}
+
+func (self *Reader) requestProtocol() scard.Protocol {
+	if self.protocol == scard.ProtocolUndefined {
+		return scard.ProtocolAny
+	}
+	return self.protocol
+}
+
+// 接続したカードとネゴシエートしたプロトコルを返します
+func (self *Reader) ActiveProtocol() scard.Protocol {
+	return self.activeProtocol
+}
+
 func (self *Reader) Finalize() {
-	self.ctx.Release()
+	if self.card != nil {
+		self.card.Disconnect(scard.LeaveCard)
+		self.card = nil
+	}
+	if self.ownsCtx && self.ctx != nil {
+		self.ctx.Release()
+	}
 }
 
 func (self *Reader) GetCard() *scard.Card {
 	card, _ := self.ctx.Connect(
-		self.name, scard.ShareExclusive, scard.ProtocolAny)
-	self.card = card
+		self.name, scard.ShareExclusive, self.requestProtocol())
+	if card != nil {
+		self.setCard(card)
+	}
 	return card
 }
 
+// 接続したカードのATRを読み取り、RegisterATRQuirksで登録された回避策と
+// ATRのカード機能から判定した拡張長の対応を適用します
+func (self *Reader) setCard(card *scard.Card) {
+	self.card = card
+	self.atr = nil
+	self.quirks = ReaderQuirks{}
+	self.activeProtocol = scard.ProtocolUndefined
+	status, err := card.Status()
+	if err != nil {
+		return
+	}
+	self.atr = status.Atr
+	self.activeProtocol = status.ActiveProtocol
+	self.quirks = lookupATRQuirks(status.Atr)
+	if atrSupportsExtendedLength(status.Atr) {
+		self.quirks.ExtendedLength = true
+	}
+}
+
+// 接続したカードのATRを返します
+func (self *Reader) ATR() []byte {
+	return self.atr
+}
+
+func (self *Reader) Quirks() ReaderQuirks {
+	return self.quirks
+}
+
+// PC/SCのGET DATA(FF CA 00 00 00)で非接触カードのUIDを取得します
+// 接触型のリーダーなどUIDを取得できない場合はエラーを返します
+func (self *Reader) GetUID() ([]byte, error) {
+	if !self.connected() {
+		return nil, newError("NOT_CONNECTED", nil)
+	}
+	data, err := self.GetReaderData(ReaderDataUID)
+	if err != nil {
+		return nil, newError("UID_UNAVAILABLE", err)
+	}
+	if len(data) == 0 {
+		return nil, newError("UID_UNAVAILABLE", nil)
+	}
+	return data, nil
+}
+
+func (self *Reader) Status() (*scard.CardStatus, error) {
+	if self.card == nil {
+		return nil, newError("NOT_CONNECTED", nil)
+	}
+	return self.card.Status()
+}
+
+// CancelContextを指定した場合は、そのキャンセルで待機を中断してctx.Err()を返します
 func (self *Reader) Connect() error {
+	ctx := self.context()
+	stop := self.cancelOnDone(ctx)
+	defer stop()
+
 	rs := make([]scard.ReaderState, 1)
 	rs[0].Reader = self.name
 	rs[0].CurrentState = scard.StateUnaware // no need
 	var err error
 	for i := 0; i < 5; i++ {
 		err = self.ctx.GetStatusChange(rs, -1)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 		if err != nil {
 			return err
 		}
 
 		if rs[0].EventState&scard.StatePresent != 0 {
 			card, e := self.ctx.Connect(
-				self.name, scard.ShareExclusive, scard.ProtocolAny)
+				self.name, scard.ShareExclusive, self.requestProtocol())
 			if e == nil {
-				self.card = card
+				self.setCard(card)
 				return nil
 			} else {
 				err = e
 			}
 		}
 		fmt.Fprintf(os.Stderr, "connecting...\n")
-		time.Sleep(1 * time.Second)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(1 * time.Second):
+		}
 	}
 	if err != nil {
 		return err
 	}
-	return errors.New("カードが見つかりません")
+	return newError("CARD_NOT_FOUND", nil)
+}
+
+// カードが挿入されるまで待機して接続します
+// ctxがキャンセルされた場合はctx.Err()を返します
+func (self *Reader) WaitForCard(ctx context.Context) error {
+	stop := self.cancelOnDone(ctx)
+	defer stop()
+
+	rs := make([]scard.ReaderState, 1)
+	rs[0].Reader = self.name
+	rs[0].CurrentState = scard.StateUnaware
+	for {
+		err := self.ctx.GetStatusChange(rs, -1)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			return err
+		}
+		if rs[0].EventState&scard.StatePresent != 0 {
+			card, err := self.ctx.Connect(
+				self.name, scard.ShareExclusive, self.requestProtocol())
+			if err == nil {
+				self.setCard(card)
+				return nil
+			}
+			// カードが安定するまで待って再試行
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(1 * time.Second):
+			}
+			rs[0].CurrentState = scard.StateUnaware
+			continue
+		}
+		rs[0].CurrentState = rs[0].EventState
+	}
+}
+
+// カードとの接続を切断し、カードが取り出されるまで待機します
+// ctxがキャンセルされた場合はctx.Err()を返します
+func (self *Reader) WaitForRemoval(ctx context.Context) error {
+	stop := self.cancelOnDone(ctx)
+	defer stop()
+
+	if self.card != nil {
+		self.card.Disconnect(scard.LeaveCard)
+		self.card = nil
+	}
+	rs := make([]scard.ReaderState, 1)
+	rs[0].Reader = self.name
+	rs[0].CurrentState = scard.StateUnaware
+	for {
+		err := self.ctx.GetStatusChange(rs, -1)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			return err
+		}
+		if rs[0].EventState&scard.StatePresent == 0 {
+			return nil
+		}
+		rs[0].CurrentState = rs[0].EventState
+	}
+}
+
+type CardType int
+
+const (
+	CardTypeUnknown CardType = iota
+	CardTypeMyNumber
+	CardTypeJuki
+)
+
+func (self CardType) String() string {
+	switch self {
+	case CardTypeMyNumber:
+		return "マイナンバーカード"
+	case CardTypeJuki:
+		return "住基カード"
+	default:
+		return "不明なカード"
+	}
+}
+
+// JPKI-APのトークン情報からカードの種別を判定します
+// JPKI-APが無いカードはCardTypeUnknownを返し、通信のエラーはそのまま返します
+func (self *Reader) IdentifyCard() (CardType, error) {
+	jpkiAP, err := self.SelectJPKIAP()
+	if errors.Is(err, ErrAPNotFound) {
+		return CardTypeUnknown, nil
+	} else if err != nil {
+		return CardTypeUnknown, err
+	}
+	token, err := jpkiAP.GetToken()
+	if err != nil {
+		return CardTypeUnknown, err
+	}
+	switch token {
+	case "JPKIAPICCTOKEN2":
+		return CardTypeMyNumber, nil
+	case "JPKIAPICCTOKEN":
+		return CardTypeJuki, nil
+	default:
+		return CardTypeUnknown, nil
+	}
+}
+
+// APをSELECTします
+// SWが6A82/6A86の場合はErrAPNotFoundと比較できるエラーを返します
+func (self *Reader) selectAP(id string) error {
+	err := self.SelectDF(id)
+	if apduErr, ok := err.(*APDUError); ok && apduErr.sw1 == 0x6A &&
+		(apduErr.sw2 == 0x82 || apduErr.sw2 == 0x86) {
+		return newError("AP_NOT_FOUND", err)
+	}
+	return err
+}
+
+// APをSELECTし、PINのEFを選択してPINを照合します
+// apには"VISUAL"、"TEXT"、"JPKI"またはAIDを指定します
+// APとEFの選択に失敗した場合は失敗した段階を示すエラーを返し、
+// 照合に失敗した場合はVerifyのエラーをそのまま返します
+func (self *Reader) AuthenticateAP(ap string, pinEF string, pin string) error {
+	aid, ok := apNames[strings.ToUpper(ap)]
+	if !ok {
+		aid = ap
+	}
+	err := self.selectAP(aid)
+	if err != nil {
+		return newError("AUTH_SELECT_AP", err, ap, err)
+	}
+	err = self.SelectEF(pinEF)
+	if err != nil {
+		return newError("AUTH_SELECT_PIN_EF", err, pinEF, err)
+	}
+	return self.Verify(pin)
+}
+
+// AuthenticateAPと同様ですが、PINはPinProviderから取得し照合後にゼロクリアします
+func (self *Reader) AuthenticateAPWithProvider(ap string, pinEF string, provider PinProvider) error {
+	aid, ok := apNames[strings.ToUpper(ap)]
+	if !ok {
+		aid = ap
+	}
+	err := self.selectAP(aid)
+	if err != nil {
+		return newError("AUTH_SELECT_AP", err, ap, err)
+	}
+	err = self.SelectEF(pinEF)
+	if err != nil {
+		return newError("AUTH_SELECT_PIN_EF", err, pinEF, err)
+	}
+	return self.VerifyWithProvider(provider)
 }
 
 func (self *Reader) SelectVisualAP() (*VisualAP, error) {
-	err := self.SelectDF("D3921000310001010402")
+	err := self.selectAP(AIDVisualAP)
 	ap := VisualAP{self}
 	return &ap, err
 }
 
 func (self *Reader) SelectTextAP() (*TextAP, error) {
-	err := self.SelectDF("D3921000310001010408")
+	err := self.selectAP(AIDTextAP)
 	ap := TextAP{self}
 	return &ap, err
 }
 
 func (self *Reader) SelectJPKIAP() (*JPKIAP, error) {
-	err := self.SelectDF("D392F000260100000001")
+	err := self.selectAP(AIDJPKIAP)
 	ap := JPKIAP{self}
 	return &ap, err
 }
@@ -128,8 +496,27 @@ func (self *Reader) SelectDF(id string) error {
 	if sw1 == 0x90 && sw2 == 0x00 {
 		return nil
 	} else {
-		return NewAPDUError(sw1, sw2)
+		return self.apduError(sw1, sw2)
+	}
+}
+
+// 任意のAIDのAPをSELECTし、応答のステータスワードを返します
+// 既知のAPはAIDVisualAPなどの定数をToBytesで変換して指定できます
+// 9000以外の場合はステータスワードと共にAPDUErrorを返します
+func (self *Reader) SelectAID(aid []byte) (StatusWord, error) {
+	if len(aid) < 5 || len(aid) > 16 {
+		return 0, newError("INVALID_AID", nil, len(aid))
+	}
+	if self.debug {
+		fmt.Fprintf(os.Stderr, "# Select AID\n")
 	}
+	apdu := NewAPDUCase3(0x00, 0xA4, 0x04, 0x0C, aid)
+	sw1, sw2, _ := self.Trans(apdu)
+	sw := NewStatusWord(sw1, sw2)
+	if !sw.IsSuccess() {
+		return sw, self.apduError(sw1, sw2)
+	}
+	return sw, nil
 }
 
 func (self *Reader) SelectEF(id string) error {
@@ -142,7 +529,7 @@ func (self *Reader) SelectEF(id string) error {
 	if sw1 == 0x90 && sw2 == 0x00 {
 		return nil
 	} else {
-		return NewAPDUError(sw1, sw2)
+		return self.apduError(sw1, sw2)
 	}
 }
 
@@ -161,41 +548,70 @@ func (self *Reader) LookupPin() int {
 
 func (self *Reader) Verify(pin string) error {
 	if pin == "" {
-		return errors.New("PINが空です")
+		return newError("PIN_EMPTY", nil)
+	}
+	bpin := []byte(pin)
+	defer zeroBytes(bpin)
+	return self.VerifyBytes(bpin)
+}
+
+// PinProviderからPINを取得して照合し、取得したPINはゼロクリアします
+func (self *Reader) VerifyWithProvider(provider PinProvider) error {
+	pin, err := provider()
+	if err != nil {
+		return err
+	}
+	defer zeroBytes(pin)
+	return self.VerifyBytes(pin)
+}
+
+func (self *Reader) VerifyBytes(pin []byte) error {
+	if len(pin) == 0 {
+		return newError("PIN_EMPTY", nil)
 	}
 	if self.debug {
 		fmt.Fprintf(os.Stderr, "# Verify PIN\n")
 	}
-	bpin := []byte(pin)
-	apdu := NewAPDUCase3(0x00, 0x20, 0x00, 0x80, bpin)
+	apdu := NewAPDUCase3(0x00, 0x20, 0x00, 0x80, pin)
+	defer zeroBytes(apdu.cmd)
 	sw1, sw2, _ := self.Trans(apdu)
 	if sw1 == 0x90 && sw2 == 0x00 {
 		return nil
-	} else if sw1 == 0x63 {
+	}
+	cause := self.apduError(sw1, sw2)
+	if sw1 == 0x63 {
 		counter := int(sw2 & 0x0F)
 		if counter == 0 {
-			return errors.New("暗証番号が間違っています。ブロックされました")
+			return newError("PIN_NOW_BLOCKED", cause)
 		}
-		return fmt.Errorf("暗証番号が間違っています。のこり%d回", counter)
+		return newError("PIN_INCORRECT", cause, counter)
 	} else if sw1 == 0x69 && sw2 == 0x84 {
-		return errors.New("暗証番号がブロックされています。")
+		return newError("PIN_BLOCKED", cause)
 	} else {
-		return fmt.Errorf("暗証番号が間違っています SW1=%02X SW2=%02X",
-			sw1, sw2)
+		return newError("PIN_VERIFY_FAILED", cause, sw1, sw2)
 	}
 }
 
 func (self *Reader) ChangePin(pin string) bool {
+	return self.changePin(pin) == nil
+}
+
+func (self *Reader) changePin(pin string) error {
+	if err := checkReadOnly(); err != nil {
+		return err
+	}
 	if self.debug {
 		fmt.Fprintf(os.Stderr, "# Change PIN\n")
 	}
 	bpin := []byte(pin)
+	defer zeroBytes(bpin)
 	apdu := NewAPDUCase3(0x00, 0x24, 0x01, 0x80, bpin)
+	defer zeroBytes(apdu.cmd)
 	sw1, sw2, _ := self.Trans(apdu)
 	if sw1 == 0x90 && sw2 == 0x00 {
-		return true
+		return nil
 	} else {
-		return false
+		return self.apduError(sw1, sw2)
 	}
 }
 
@@ -213,7 +629,6 @@ func dumpBinary(bin []byte) {
 }
 
 func (self *Reader) Trans(apdu *APDU) (uint8, uint8, []byte) {
-	card := self.card
 	cmd := apdu.cmd
 	if self.debug {
 		if len(cmd) > 4 && cmd[0] == 0x00 && cmd[1] == 0x20 {
@@ -224,7 +639,11 @@ func (self *Reader) Trans(apdu *APDU) (uint8, uint8, []byte) {
 			fmt.Fprintf(os.Stderr, "< % X\n", cmd)
 		}
 	}
-	res, err := card.Transmit(cmd)
+	res, err := self.transmit(cmd)
+	self.timeoutErr = nil
+	if errors.Is(err, ErrAPDUTimeout) {
+		self.timeoutErr = err
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "err: %s\n", err)
 		return 0, 0, nil
@@ -234,6 +653,14 @@ func (self *Reader) Trans(apdu *APDU) (uint8, uint8, []byte) {
 		dumpBinary(res)
 	}
 
+	for self.quirks.GetResponse && len(res) >= 2 && res[len(res)-2] == 0x61 {
+		res, err = self.getResponse(res)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "err: %s\n", err)
+			return 0, 0, nil
+		}
+	}
+
 	l := len(res)
 	if l == 2 {
 		return res[0], res[1], nil
@@ -243,7 +670,38 @@ func (self *Reader) Trans(apdu *APDU) (uint8, uint8, []byte) {
 	return 0, 0, nil
 }
 
+// SW1=61の応答の残りをGET RESPONSEで取得し、受信済みのデータに連結します
+func (self *Reader) getResponse(res []byte) ([]byte, error) {
+	l := len(res)
+	cmd := NewAPDUCase2(0x00, 0xC0, 0x00, 0x00, res[l-1]).cmd
+	if self.debug {
+		fmt.Fprintf(os.Stderr, "< % X\n", cmd)
+	}
+	next, err := self.transmit(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if self.debug {
+		dumpBinary(next)
+	}
+	return append(res[:l-2:l-2], next...), nil
+}
+
+// 読み取り済みのバイト数と全体のバイト数を受け取るコールバック
+type ProgressFunc func(read int, total int)
+
 func (self *Reader) ReadBinary(size uint16) []byte {
+	return self.ReadBinaryWithProgress(size, nil)
+}
+
+// READ BINARYの応答を受信するたびにprogressを呼び出します
+func (self *Reader) ReadBinaryWithProgress(size uint16, progress ProgressFunc) []byte {
+	data, _ := self.readBinary(size, progress)
+	return data
+}
+
+// 読み取りに失敗した場合はSWに応じたエラーを返します
+func (self *Reader) readBinary(size uint16, progress ProgressFunc) ([]byte, error) {
 	if self.debug {
 		fmt.Fprintf(os.Stderr, "# Read Binary\n")
 	}
@@ -252,25 +710,150 @@ func (self *Reader) ReadBinary(size uint16) []byte {
 	var pos uint16
 	pos = 0
 	var res []byte
+	chunk := self.quirks.readChunkSize()
 
 	for pos < size {
-		if size-pos > 0xFF {
-			l = 0
-		} else {
-			l = uint8(size - pos)
+		// T=0では拡張長のAPDUをそのまま送れない
+		if self.quirks.ExtendedLength && self.activeProtocol != scard.ProtocolT0 {
+			n := size - pos
+			if n > extendedReadChunkSize {
+				n = extendedReadChunkSize
+			}
+			apdu := NewAPDUCase2Extended(0x00, 0xB0, uint8(pos>>8&0xFF), uint8(pos&0xFF), n)
+			sw1, sw2, data := self.Trans(apdu)
+			if sw1 == 0x90 && sw2 == 0x00 && len(data) > 0 {
+				res = append(res, data...)
+				pos += uint16(len(data))
+				if progress != nil {
+					progress(int(pos), int(size))
+				}
+				continue
+			}
+			// リーダーが拡張長に対応していない場合などは短縮長で読み直す
+			self.quirks.ExtendedLength = false
+			if self.debug {
+				fmt.Fprintf(os.Stderr, "# Read Binary: extended length is not available\n")
+			}
+		}
+		n := chunk
+		if size-pos < chunk {
+			n = size - pos
 		}
+		l = uint8(n) // 256の場合はLe=00
 		apdu := NewAPDUCase2(0x00, 0xB0, uint8(pos>>8&0xFF), uint8(pos&0xFF), l)
 		sw1, sw2, data := self.Trans(apdu)
+		if sw1 == 0x67 && sw2 == 0x00 && self.quirks.shrinkReadChunk(int(n)) {
+			chunk = self.quirks.readChunkSize()
+			if self.debug {
+				fmt.Fprintf(os.Stderr, "# Read Binary: retry with %d bytes\n", chunk)
+			}
+			continue
+		}
 		if sw1 != 0x90 || sw2 != 0x00 {
-			return nil
+			return nil, self.readError(sw1, sw2)
 		}
 		res = append(res, data...)
 		pos += uint16(len(data))
+		if progress != nil {
+			progress(int(pos), int(size))
+		}
+	}
+	return res, nil
+}
+
+// EFのサイズが不明な場合にLe=00で末尾まで読み取ります
+// SW1=61の場合はGET RESPONSEで、SW1=6Cの場合は指定された長さで読み直します
+func (self *Reader) ReadBinaryAll(efid string) ([]byte, error) {
+	err := self.SelectEF(efid)
+	if err != nil {
+		return nil, err
+	}
+	if self.debug {
+		fmt.Fprintf(os.Stderr, "# Read Binary All\n")
+	}
+
+	var res []byte
+	var le uint8 = 0
+	for {
+		pos := len(res)
+		// P1の最上位ビットはSFI指定に使われるため、オフセットは15ビットまでです
+		if pos > 0x7FFF {
+			return nil, newError("EF_TOO_LARGE", nil, efid)
+		}
+		apdu := NewAPDUCase2(0x00, 0xB0, uint8(pos>>8&0x7F), uint8(pos&0xFF), le)
+		sw1, sw2, data := self.Trans(apdu)
+		var chunk []byte
+		for sw1 == 0x61 {
+			chunk = append(chunk, data...)
+			sw1, sw2, data = self.Trans(NewAPDUCase2(0x00, 0xC0, 0x00, 0x00, sw2))
+		}
+		chunk = append(chunk, data...)
+		switch {
+		case sw1 == 0x90 && sw2 == 0x00:
+			res = append(res, chunk...)
+			// 6Cで指定された長さは残りの全てなので、それを読めば末尾です
+			if le != 0 || len(chunk) < 0x100 {
+				return res, nil
+			}
+		case sw1 == 0x6C:
+			// 6C00はLe=00(256バイト)と同じ要求なので、読み直しても同じ応答になります
+			if le != 0 || sw2 == 0 {
+				return nil, newError("READ_BINARY_RETRY", nil, sw2)
+			}
+			le = sw2
+		case sw1 == 0x62 && sw2 == 0x82: // 要求した長さより前にEFの末尾に達した
+			return append(res, chunk...), nil
+		case sw1 == 0x6B && sw2 == 0x00 && pos > 0: // オフセットがEFの範囲外
+			return res, nil
+		default:
+			return nil, self.apduError(sw1, sw2)
+		}
+	}
+}
+
+// EFをSELECTし、呼び出し側で確保したbufに読み取ります
+// bufが埋まるかEFの末尾に達するまで読み取り、読み取ったバイト数を返します
+func (self *Reader) ReadBinaryInto(efid string, buf []byte) (int, error) {
+	err := self.SelectEF(efid)
+	if err != nil {
+		return 0, err
+	}
+	if self.debug {
+		fmt.Fprintf(os.Stderr, "# Read Binary Into\n")
+	}
+
+	chunk := int(self.quirks.readChunkSize())
+	n := 0
+	for n < len(buf) && n <= 0x7FFF {
+		l := len(buf) - n
+		if l > chunk {
+			l = chunk
+		}
+		apdu := NewAPDUCase2(0x00, 0xB0, uint8(n>>8&0x7F), uint8(n&0xFF), uint8(l))
+		sw1, sw2, data := self.Trans(apdu)
+		switch {
+		case sw1 == 0x90 && sw2 == 0x00:
+			if len(data) == 0 {
+				return n, nil
+			}
+			n += copy(buf[n:], data)
+		case sw1 == 0x62 && sw2 == 0x82: // 要求した長さより前にEFの末尾に達した
+			return n + copy(buf[n:], data), nil
+		case sw1 == 0x6B && sw2 == 0x00 && n > 0: // オフセットがEFの範囲外
+			return n, nil
+		case sw1 == 0x67 && sw2 == 0x00 && self.quirks.shrinkReadChunk(l):
+			chunk = int(self.quirks.readChunkSize())
+		default:
+			return n, self.readError(sw1, sw2)
+		}
 	}
-	return res
+	return n, nil
 }
 
 func (self *Reader) Signature(data []byte) ([]byte, error) {
+	if err := checkReadOnly(); err != nil {
+		return nil, err
+	}
 	if self.debug {
 		fmt.Fprintf(os.Stderr, "# Signature\n")
 	}
@@ -280,6 +863,7 @@ func (self *Reader) Signature(data []byte) ([]byte, error) {
 	if sw1 == 0x90 && sw2 == 0x00 {
 		return res, nil
 	} else {
-		return nil, fmt.Errorf("署名エラー(%0X, %0X)", sw1, sw2)
+		return nil, newError("SIGNATURE_FAILED", self.apduError(sw1, sw2),
+			sw1, sw2)
 	}
 }
@@ -0,0 +1,519 @@
+// Session API
+
+package libmyna
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ebfe/scard"
+)
+
+// リーダーとの接続を保持し、複数の操作で使い回すためのセッション
+// 各メソッドは排他制御されているため複数のgoroutineから呼び出せます
+type Session struct {
+	mutex   sync.Mutex
+	reader  *Reader
+	secrets [][]byte // Closeでゼロクリアするバッファ
+}
+
+func NewSession(opts ...func(*Reader)) (*Session, error) {
+	reader, err := NewReader(opts...)
+	if err != nil {
+		return nil, err
+	}
+	err = reader.Connect()
+	if err != nil {
+		reader.Finalize()
+		return nil, err
+	}
+	session := Session{reader: reader}
+	return &session, nil
+}
+
+// 指定したTransportで通信するセッションを作成します
+func NewSessionWithTransport(transport Transport, opts ...func(*Reader)) *Session {
+	session := Session{reader: NewReaderWithTransport(transport, opts...)}
+	return &session
+}
+
+func (self *Session) Reader() *Reader {
+	return self.reader
+}
+
+// セッションを終了します
+// GetMyNumberBytesなどで返したバイト列はゼロクリアされるため、Close後は使用できません
+// stringは変更できずゼロクリアされないので、秘密情報はPinProviderとバイト列を返すAPIで扱ってください
+func (self *Session) Close() {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	for _, secret := range self.secrets {
+		zeroBytes(secret)
+	}
+	self.secrets = nil
+	self.reader.Finalize()
+}
+
+// Closeでゼロクリアするバッファとして登録します
+func (self *Session) retain(secret []byte) []byte {
+	self.secrets = append(self.secrets, secret)
+	return secret
+}
+
+// カードが抜き差しされていた場合は再接続します
+func (self *Session) ensureCard() error {
+	if self.reader.transport != nil {
+		return nil
+	}
+	if self.reader.card != nil {
+		_, err := self.reader.card.Status()
+		if err == nil {
+			return nil
+		}
+		self.reader.card.Disconnect(scard.LeaveCard)
+		self.reader.card = nil
+	}
+	return self.reader.Connect()
+}
+
+// 照合済みのPINの状態を破棄し、次の操作で再度PINを要求させます
+// APの再選択ではカードによって状態が残る場合があるため、
+// リーダーとの接続は保ったままカードをリセットします
+// Transportを使う場合はリセットできないため、券面APを選択して他のAPの状態を破棄します
+func (self *Session) ResetAuth() error {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	if self.reader.transport != nil {
+		_, err := self.reader.SelectVisualAP()
+		return err
+	}
+	if self.reader.card == nil {
+		return nil
+	}
+	card := self.reader.card
+	err := card.Reconnect(scard.ShareExclusive, self.reader.requestProtocol(), scard.ResetCard)
+	if err != nil {
+		card.Disconnect(scard.LeaveCard)
+		self.reader.card = nil
+		return err
+	}
+	self.reader.setCard(card)
+	return nil
+}
+
+func (self *Session) GetMyNumber(pin string) (string, error) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	if err := self.ensureCard(); err != nil {
+		return "", err
+	}
+	err := self.reader.AuthenticateAP("TEXT", "0011", pin)
+	if err != nil {
+		return "", err
+	}
+	textAP := TextAP{self.reader}
+	return textAP.ReadMyNumber()
+}
+
+// PinProviderから取得したPINで照合し、マイナンバーをバイト列で返します
+// PINは照合後に、返したバイト列はCloseでゼロクリアされます
+func (self *Session) GetMyNumberBytes(provider PinProvider) ([]byte, error) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	if err := self.ensureCard(); err != nil {
+		return nil, err
+	}
+	err := self.reader.AuthenticateAPWithProvider("TEXT", "0011", provider)
+	if err != nil {
+		return nil, err
+	}
+	textAP := TextAP{self.reader}
+	mynumber, err := textAP.readMyNumberBytes()
+	if err != nil {
+		return nil, err
+	}
+	return self.retain(mynumber), nil
+}
+
+func (self *Session) GetAttrInfo(pin string) (*TextAttrs, error) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	if err := self.ensureCard(); err != nil {
+		return nil, err
+	}
+	err := self.reader.AuthenticateAP("TEXT", "0011", pin)
+	if err != nil {
+		return nil, err
+	}
+	textAP := TextAP{self.reader}
+	return textAP.ReadAttributes()
+}
+
+// 券面入力補助APの住所から市区町村を判定し、地方公共団体コードと団体名を返します
+// 判定できない場合はエラーにせず、コードを空文字列にして返します
+func (self *Session) GetIssuingMunicipality(pin string) (string, string, error) {
+	attrs, err := self.GetAttrInfo(pin)
+	if err != nil {
+		return "", "", err
+	}
+	return municipalityFromAddress(attrs.Address)
+}
+
+// 基本4情報のEFを読み取り、TLVの構造のまま返します
+// カードの世代による格納形式の違いを調べるためのものです
+func (self *Session) DumpAttrDER(pin string) ([]TLV, error) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	if err := self.ensureCard(); err != nil {
+		return nil, err
+	}
+	err := self.reader.AuthenticateAP("TEXT", "0011", pin)
+	if err != nil {
+		return nil, err
+	}
+	textAP := TextAP{self.reader}
+	data, err := textAP.ReadAttributesRaw()
+	if err != nil {
+		return nil, err
+	}
+	return ParseTLV(data)
+}
+
+// 1回のPIN照合でマイナンバーと基本4情報を読み取ります
+func (self *Session) GetIdentity(pin string) (*Identity, error) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	if err := self.ensureCard(); err != nil {
+		return nil, err
+	}
+	err := self.reader.AuthenticateAP("TEXT", "0011", pin)
+	if err != nil {
+		return nil, err
+	}
+	textAP := TextAP{self.reader}
+	mynumber, err := textAP.ReadMyNumber()
+	if err != nil {
+		return nil, err
+	}
+	attrs, err := textAP.ReadAttributes()
+	if err != nil {
+		return nil, err
+	}
+	return &Identity{MyNumber: mynumber, Attrs: attrs}, nil
+}
+
+func (self *Session) GetVerifiableBirthDate(pin string) (*SignedBirthDate, error) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	if err := self.ensureCard(); err != nil {
+		return nil, err
+	}
+	err := self.reader.AuthenticateAP("TEXT", "0011", pin)
+	if err != nil {
+		return nil, err
+	}
+	textAP := TextAP{self.reader}
+	raw, err := textAP.ReadAttributesRaw()
+	if err != nil {
+		return nil, err
+	}
+	attrs, err := parseTextAttrs(raw, self.reader.attrDecoder)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := textAP.ReadSignature()
+	if err != nil {
+		return nil, err
+	}
+	cert, err := textAP.ReadCertificate()
+	if err != nil {
+		return nil, err
+	}
+	result := SignedBirthDate{
+		Birth:       attrs.Birth,
+		Attrs:       raw,
+		Signature:   signature,
+		Certificate: cert,
+	}
+	return &result, nil
+}
+
+// 券面事項入力補助PINが照合済みであることを前提に基本4情報を読み取ります
+// APのSELECTとPINの照合を行わないため、GetAttrInfoの後に繰り返し呼び出せます
+func (self *Session) ReadAttrInfo() (*TextAttrs, error) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	if !self.reader.connected() {
+		return nil, newError("NOT_CONNECTED", nil)
+	}
+	textAP := TextAP{self.reader}
+	return textAP.ReadAttributes()
+}
+
+func (self *Session) GetJPKICertRaw(efid string, pin string) ([]byte, error) {
+	return self.GetJPKICertRawWithOpts(efid, CertReadOpts{Pin: pin})
+}
+
+func (self *Session) GetJPKICertRawWithOpts(efid string, opts CertReadOpts) ([]byte, error) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	if err := self.ensureCard(); err != nil {
+		return nil, err
+	}
+	jpkiAP, err := self.reader.SelectJPKIAP()
+	if err != nil {
+		return nil, err
+	}
+	if opts.Pin != "" {
+		err = jpkiAP.VerifySignPin(opts.Pin)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return jpkiAP.ReadCertificateRawWithProgress(efid, opts.Progress)
+}
+
+// 利用者証明用証明書をdirにキャッシュし、カードのUIDが一致する間はキャッシュを返します
+// UIDが異なる場合はカードから読み直してキャッシュを更新します
+// UIDを取得できないリーダーではキャッシュを使わずに毎回カードから読み取ります
+// キャッシュはUIDだけで照合するため、カードの真正性の確認には使わないでください
+func (self *Session) GetJPKIAuthCertCached(dir string) (*x509.Certificate, error) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	if err := self.ensureCard(); err != nil {
+		return nil, err
+	}
+	uid, err := self.reader.GetUID()
+	if err != nil {
+		return self.readAuthCert()
+	}
+	path := filepath.Join(dir, authCertCacheName)
+	if cert := readCachedCert(path, uid); cert != nil {
+		return cert, nil
+	}
+	cert, err := self.readAuthCert()
+	if err != nil {
+		return nil, err
+	}
+	err = writeFileAtomic(path, 0644, func(w io.Writer) error {
+		return pem.Encode(w, &pem.Block{
+			Type:    "CERTIFICATE",
+			Headers: map[string]string{"UID": hex.EncodeToString(uid)},
+			Bytes:   cert.Raw,
+		})
+	})
+	if err != nil && self.reader.debug {
+		fmt.Fprintf(os.Stderr, "# 証明書のキャッシュを書き込めません: %s\n", err)
+	}
+	return cert, nil
+}
+
+func (self *Session) readAuthCert() (*x509.Certificate, error) {
+	jpkiAP, err := self.reader.SelectJPKIAP()
+	if err != nil {
+		return nil, err
+	}
+	return jpkiAP.ReadCertificate(self.reader.profile.AuthCertEF)
+}
+
+const authCertCacheName = "jpki_auth_cert.pem"
+
+// キャッシュのUIDが一致する場合のみ証明書を返します
+func readCachedCert(path string, uid []byte) *x509.Certificate {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Headers["UID"] != hex.EncodeToString(uid) {
+		return nil
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil
+	}
+	return cert
+}
+
+// ダイジェスト値に署名用秘密鍵で署名します
+func (self *Session) SignDigest(pin string, hash crypto.Hash, digest []byte) ([]byte, error) {
+	if err := checkReadOnly(); err != nil {
+		return nil, err
+	}
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	if err := self.ensureCard(); err != nil {
+		return nil, err
+	}
+	digestInfo, err := signerDigestInfo(digest, hash)
+	if err != nil {
+		return nil, err
+	}
+	err = self.reader.AuthenticateAP("JPKI", self.reader.profile.SignPinEF, pin)
+	if err != nil {
+		return nil, err
+	}
+	jpkiAP := JPKIAP{self.reader}
+	return jpkiAP.SignWithSignKey(digestInfo)
+}
+
+// 利用者証明用秘密鍵でnonceと発行時刻に署名したログイン用アサーションを作成します
+func (self *Session) SignLoginAssertion(pin string, nonce []byte) ([]byte, error) {
+	if err := checkReadOnly(); err != nil {
+		return nil, err
+	}
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	if err := self.ensureCard(); err != nil {
+		return nil, err
+	}
+	jpkiAP, err := self.reader.SelectJPKIAP()
+	if err != nil {
+		return nil, err
+	}
+	cert, err := jpkiAP.ReadCertificate(self.reader.profile.AuthCertEF)
+	if err != nil {
+		return nil, err
+	}
+	err = jpkiAP.VerifyAuthPin(pin)
+	if err != nil {
+		return nil, err
+	}
+	return buildLoginAssertion(cert, nonce, time.Now(), jpkiAP.SignWithAuthKey)
+}
+
+// 利用者証明用証明書とCA証明書をPIN無しで読み取り、rootsまで検証できるか確認します
+// 検証できない場合はfalseを返します。エラーを返すのは読み取りに失敗した場合のみです
+// 証明書は複製できるため、鍵を持っていることまでは確認できません
+func (self *Session) VerifyCardAuthenticity(roots *x509.CertPool) (bool, error) {
+	if roots == nil {
+		return false, newError("ROOTS_NOT_CONFIGURED", nil)
+	}
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	if err := self.ensureCard(); err != nil {
+		return false, err
+	}
+	jpkiAP, err := self.reader.SelectJPKIAP()
+	if err != nil {
+		return false, err
+	}
+	cert, err := jpkiAP.ReadCertificate(self.reader.profile.AuthCertEF)
+	if err != nil {
+		return false, err
+	}
+	cacert, err := jpkiAP.ReadCertificate(self.reader.profile.AuthCACertEF)
+	if err != nil {
+		return false, err
+	}
+	intermediates := x509.NewCertPool()
+	intermediates.AddCert(cacert)
+	_, err = cert.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err == nil, nil
+}
+
+// Provisionで読み取る利用者の登録情報
+type ProvisionBundle struct {
+	AuthCert *x509.Certificate
+	SignCert *x509.Certificate
+	Attrs    *TextAttrs
+}
+
+// 1回の接続で利用者証明用証明書・署名用証明書・基本4情報を読み取ります
+// 署名用パスワードと券面事項入力補助用PINはそれぞれ1回だけ照合します
+func (self *Session) Provision(signPin string, helperPin string) (*ProvisionBundle, error) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	if err := self.ensureCard(); err != nil {
+		return nil, err
+	}
+	jpkiAP, err := self.reader.SelectJPKIAP()
+	if err != nil {
+		return nil, err
+	}
+	bundle := ProvisionBundle{}
+	bundle.AuthCert, err = jpkiAP.ReadCertificate(self.reader.profile.AuthCertEF)
+	if err != nil {
+		return nil, err
+	}
+	err = jpkiAP.VerifySignPin(signPin)
+	if err != nil {
+		return nil, err
+	}
+	bundle.SignCert, err = jpkiAP.ReadCertificate(self.reader.profile.SignCertEF)
+	if err != nil {
+		return nil, err
+	}
+
+	err = self.reader.AuthenticateAP("TEXT", "0011", helperPin)
+	if err != nil {
+		return nil, err
+	}
+	textAP := TextAP{self.reader}
+	bundle.Attrs, err = textAP.ReadAttributes()
+	if err != nil {
+		return nil, err
+	}
+	return &bundle, nil
+}
+
+// contentのダイジェスト値からCMSの署名対象の属性を作成し、署名用秘密鍵で署名します
+// attrsはSET OFとしてエンコードした属性(署名の対象)です
+// SignerInfoのsignedAttrsに格納する場合はタグを[0] IMPLICITに付け替えてください
+func (self *Session) HashAndSign(pin string, content []byte, hash crypto.Hash) ([]byte, []byte, *x509.Certificate, error) {
+	if err := checkReadOnly(); err != nil {
+		return nil, nil, nil, err
+	}
+	if _, ok := digestInfoPrefix[hash]; !ok || !hash.Available() {
+		return nil, nil, nil, newError("UNSUPPORTED_DIGEST", nil,
+			hash.String(), strings.Join(SupportedDigests(), ", "))
+	}
+	h := hash.New()
+	h.Write(content)
+	attrsDer, err := buildCmsSignedAttrs(h.Sum(nil))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	attrs, err := asn1.Marshal(asn1.RawValue{
+		Tag: asn1.TagSet, IsCompound: true, Bytes: attrsDer})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	if err = self.ensureCard(); err != nil {
+		return nil, nil, nil, err
+	}
+	err = self.reader.AuthenticateAP("JPKI", self.reader.profile.SignPinEF, pin)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	jpkiAP := JPKIAP{self.reader}
+	cert, err := jpkiAP.ReadCertificate(self.reader.profile.SignCertEF)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	h = hash.New()
+	h.Write(attrs)
+	signature, err := jpkiAP.SignWithSignKey(makeDigestInfo(hash, h.Sum(nil)))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return attrs, signature, cert, nil
+}
@@ -1,10 +1,12 @@
 package libmyna
 
 import (
-	"errors"
+	"crypto/rsa"
 	"fmt"
 	"github.com/jpki/myna/asn1"
+	"math/big"
 	"strconv"
+	"unicode/utf8"
 )
 
 type TextAP struct {
@@ -19,6 +21,82 @@ type TextAttrs struct {
 	Sex     string `asn1:"private,tag:37"`
 }
 
+// 氏名・住所をUTF-8の文字列として読み取るための構造
+// UTF-8以外で格納されている場合はAttrDecoderで変換します
+type textAttrsRaw struct {
+	Header  []byte `asn1:"private,tag:33"`
+	Name    []byte `asn1:"private,tag:34"`
+	Address []byte `asn1:"private,tag:35"`
+	Birth   string `asn1:"private,tag:36"`
+	Sex     string `asn1:"private,tag:37"`
+}
+
+// UTF-8以外の文字コード(Shift_JISなど)で格納された氏名・住所を変換する関数を指定します
+// golang.org/x/text/encoding/japaneseのデコーダーなどを渡してください
+// UTF-8として正しいバイト列は変換せずにそのまま使います
+func AttrDecoder(decode func([]byte) (string, error)) func(*Reader) {
+	return func(r *Reader) {
+		r.attrDecoder = decode
+	}
+}
+
+func decodeAttrString(data []byte, decode func([]byte) (string, error)) (string, error) {
+	if utf8.Valid(data) {
+		return string(data), nil
+	}
+	if decode == nil {
+		return "", newError("UNKNOWN_CHARSET", nil)
+	}
+	return decode(data)
+}
+
+// 基本4情報のDERを解析します
+func parseTextAttrs(data []byte, decode func([]byte) (string, error)) (*TextAttrs, error) {
+	var raw textAttrsRaw
+	_, err := asn1.UnmarshalWithParams(data, &raw, "private,tag:32")
+	if err != nil {
+		return nil, err
+	}
+	attrs := TextAttrs{
+		Header: raw.Header,
+		Birth:  raw.Birth,
+		Sex:    raw.Sex,
+	}
+	attrs.Name, err = decodeAttrString(raw.Name, decode)
+	if err != nil {
+		return nil, err
+	}
+	attrs.Address, err = decodeAttrString(raw.Address, decode)
+	if err != nil {
+		return nil, err
+	}
+	return &attrs, nil
+}
+
+// 基本4情報のDERから、ヘッダーと4属性(DF21-DF25)以外の要素(署名など)を連結して返します
+// dataにはTextAP.ReadAttributesRawで読み取ったバイト列を指定します
+func TextAttrsTrailer(data []byte) ([]byte, error) {
+	var outer asn1.RawValue
+	_, err := asn1.UnmarshalWithParams(data, &outer, "private,tag:32")
+	if err != nil {
+		return nil, err
+	}
+	var trailer []byte
+	rest := outer.Bytes
+	for len(rest) > 0 {
+		var elem asn1.RawValue
+		rest, err = asn1.Unmarshal(rest, &elem)
+		if err != nil {
+			return nil, err
+		}
+		if elem.Class == asn1.ClassPrivate && elem.Tag >= 33 && elem.Tag <= 37 {
+			continue
+		}
+		trailer = append(trailer, elem.FullBytes...)
+	}
+	return trailer, nil
+}
+
 type TextSignature struct {
 	MyNumDigest []byte `asn1:"private,tag:49"`
 	AttrsDigest []byte `asn1:"private,tag:50"`
@@ -29,6 +107,25 @@ type TextCertificate struct {
 	Raw []byte `asn1:"application,tag:78"`
 }
 
+// 証明書の公開鍵(7F49)からRSA公開鍵を取り出します
+// 81がモジュラス、82が公開指数です
+func (self *TextCertificate) PublicKey() (*rsa.PublicKey, error) {
+	key := findTLV(self.Raw, 0x7F49)
+	if key == nil {
+		return nil, newError("INVALID_TEXT_CERT", nil)
+	}
+	modulus := findTLV(key, 0x81)
+	exponent := findTLV(key, 0x82)
+	if len(modulus) == 0 || len(exponent) == 0 || len(exponent) > 4 {
+		return nil, newError("INVALID_TEXT_CERT", nil)
+	}
+	e := 0
+	for _, b := range exponent {
+		e = e<<8 | int(b)
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(modulus), E: e}, nil
+}
+
 type TextBasicInfo struct {
 	APInfo []byte `asn1:"private,tag:65"`
 	KeyID  []byte `asn1:"private,tag:66"`
@@ -89,52 +186,72 @@ func (self *TextAP) VerifyPinB(pin string) error {
 }
 
 func (self *TextAP) ReadMyNumber() (string, error) {
-	err := self.reader.SelectEF("0001")
+	mynumber, err := self.readMyNumberBytes()
 	if err != nil {
 		return "", err
 	}
+	defer zeroBytes(mynumber)
+	return string(mynumber), nil
+}
+
+// 読み取ったEFのバッファはゼロクリアし、マイナンバーの部分だけを複製して返します
+func (self *TextAP) readMyNumberBytes() ([]byte, error) {
+	err := self.reader.SelectEF("0001")
+	if err != nil {
+		return nil, err
+	}
 	data := self.reader.ReadBinary(17)
+	defer zeroBytes(data)
 	var mynumber asn1.RawValue
 	_, err = asn1.UnmarshalWithParams(data, &mynumber, "private,tag:16")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return string(mynumber.Bytes), nil
+	return append([]byte{}, mynumber.Bytes...), nil
 }
 
 func (self *TextAP) ReadAttributes() (*TextAttrs, error) {
-	err := self.reader.SelectEF("0002")
+	data, err := self.ReadAttributesRaw()
 	if err != nil {
 		return nil, err
 	}
+	return parseTextAttrs(data, self.reader.attrDecoder)
+}
 
-	data := self.reader.ReadBinary(7)
-	if len(data) != 7 {
-		return nil, errors.New("Error at ReadBinary()")
+// 基本4情報のEFをDERのまま読み取ります
+// 署名(TextSignature.AttrsDigest)の検証にはこのバイト列を使います
+func (self *TextAP) ReadAttributesRaw() ([]byte, error) {
+	size := self.reader.profile.AttrsSize
+	if size == 0 {
+		var err error
+		size, err = self.reader.selectEFSize("0002")
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		err := self.reader.SelectEF("0002")
+		if err != nil {
+			return nil, err
+		}
 	}
-
-	parser := ASN1PartialParser{}
-	err = parser.Parse(data)
+	data := self.reader.ReadBinary(size)
+	var raw asn1.RawValue
+	rest, err := asn1.Unmarshal(data, &raw)
 	if err != nil {
 		return nil, err
 	}
-	data = self.reader.ReadBinary(parser.GetSize())
-	var attrs TextAttrs
-	_, err = asn1.UnmarshalWithParams(data, &attrs, "private,tag:32")
-	if err != nil {
-		return nil, err
-	}
-	return &attrs, nil
+	return data[:len(data)-len(rest)], nil
 }
 
+// 署名のEFはFCIまたは先頭のTLVの長さから判定したサイズで読み取ります
 func (self *TextAP) ReadSignature() (*TextSignature, error) {
-	err := self.reader.SelectEF("0003")
+	size, err := self.reader.selectEFSize("0003")
 	if err != nil {
 		return nil, err
 	}
-	data := self.reader.ReadBinary(336)
-	if len(data) != 336 {
-		return nil, errors.New("Error at ReadBinary()")
+	data := trimTLV(self.reader.ReadBinary(size))
+	if len(data) == 0 {
+		return nil, newError("READ_BINARY_FAILED", nil)
 	}
 	var signature TextSignature
 	_, err = asn1.UnmarshalWithParams(data, &signature, "private,tag:48")
@@ -151,7 +268,7 @@ func (self *TextAP) ReadCertificate() (*TextCertificate, error) {
 	}
 	data := self.reader.ReadBinary(568)
 	if len(data) != 568 {
-		return nil, errors.New("Error at ReadBinary()")
+		return nil, newError("READ_BINARY_FAILED", nil)
 	}
 	var certificate TextCertificate
 	_, err = asn1.UnmarshalWithParams(data, &certificate, "application,tag:33")
@@ -168,7 +285,7 @@ func (self *TextAP) ReadBasicInfo() (*TextBasicInfo, error) {
 	}
 	data := self.reader.ReadBinary(256)
 	if len(data) != 256 {
-		return nil, errors.New("Error at ReadBinary()")
+		return nil, newError("READ_BINARY_FAILED", nil)
 	}
 	var basicInfo TextBasicInfo
 	_, err = asn1.UnmarshalWithParams(data, &basicInfo, "private,tag:64")
@@ -177,10 +294,10 @@ func (self *TextAP) ReadBasicInfo() (*TextBasicInfo, error) {
 	}
 
 	if len(basicInfo.APInfo) != 4 {
-		return nil, errors.New("invalid APInfo length")
+		return nil, newError("INVALID_APINFO", nil)
 	}
 	if len(basicInfo.KeyID) != 16 {
-		return nil, errors.New("invalid KeyID length")
+		return nil, newError("INVALID_KEYID", nil)
 	}
 	return &basicInfo, nil
 }
@@ -192,18 +309,41 @@ func (self *TextAttrs) HeaderString() string {
 
 // ISO5218コードから日本語文字列に変換
 func (self *TextAttrs) SexString() string {
-	n, err := strconv.Atoi(self.Sex)
+	return SexString(self.Sex, "ja")
+}
+
+// ISO5218コードをlangの表記に変換します (SexStringを参照)
+func (self *TextAttrs) SexName(lang string) string {
+	return SexString(self.Sex, lang)
+}
+
+var sexLabels = map[string][4]string{
+	"ja": {"男性", "女性", "適用不能", "不明"},
+	"en": {"male", "female", "not applicable", "unknown"},
+}
+
+// ISO5218コードを文字列に変換します
+// langには"ja"または"en"を指定します。それ以外の場合は"ja"として扱います
+func SexString(code string, lang string) string {
+	labels, ok := sexLabels[lang]
+	if !ok {
+		labels = sexLabels["ja"]
+	}
+	n, err := strconv.Atoi(code)
 	if err != nil {
+		if lang == "en" {
+			return "error"
+		}
 		return "エラー"
 	}
 	switch n {
 	case 1:
-		return "男性"
+		return labels[0]
 	case 2:
-		return "女性"
+		return labels[1]
 	case 9:
-		return "適用不能"
+		return labels[2]
 	default:
-		return "不明"
+		return labels[3]
 	}
 }
@@ -0,0 +1,115 @@
+package libmyna
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSexString(t *testing.T) {
+	tests := []struct {
+		code string
+		lang string
+		want string
+	}{
+		{"1", "ja", "男性"},
+		{"2", "ja", "女性"},
+		{"9", "ja", "適用不能"},
+		{"0", "ja", "不明"},
+		{"1", "en", "male"},
+		{"2", "en", "female"},
+		{"x", "en", "error"},
+		{"2", "fr", "女性"},
+	}
+	for _, test := range tests {
+		got := SexString(test.code, test.lang)
+		if got != test.want {
+			t.Errorf("SexString(%q, %q) = %q, want %q",
+				test.code, test.lang, got, test.want)
+		}
+	}
+	attrs := TextAttrs{Sex: "2"}
+	if got := attrs.SexName("en"); got != "female" {
+		t.Errorf("SexName(en) = %q, want female", got)
+	}
+}
+
+func TestReadAttributesWithSize(t *testing.T) {
+	attrs := []byte{0xFF, 0x20, 0x1D,
+		0xDF, 0x21, 0x01, 0x00,
+		0xDF, 0x22, 0x03, 'A', 'B', 'C',
+		0xDF, 0x23, 0x01, 'X',
+		0xDF, 0x24, 0x08, '2', '0', '0', '0', '0', '1', '0', '1',
+		0xDF, 0x25, 0x01, '1'}
+	padded := append(attrs[:len(attrs):len(attrs)], make([]byte, 0x40-len(attrs))...)
+	card := mapTransport{
+		"00 A4 02 0C 02 00 02": {0x90, 0x00},
+		"00 B0 00 00 40":       append(padded, 0x90, 0x00),
+	}
+	profile := DefaultCardProfile
+	profile.AttrsSize = 0x40
+	textAP := TextAP{NewReaderWithTransport(card, Profile(profile))}
+	got, err := textAP.ReadAttributes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "ABC" || got.Sex != "1" {
+		t.Errorf("unexpected attributes: %+v", got)
+	}
+	raw, err := textAP.ReadAttributesRaw()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(raw, attrs) {
+		t.Errorf("ReadAttributesRaw should trim the padding: % X", raw)
+	}
+}
+
+func TestParseTextAttrsCharset(t *testing.T) {
+	sjis := []byte{0x8E, 0x52, 0x93, 0x63} // Shift_JISの"山田"
+	data := []byte{0xFF, 0x20, 0x1E,
+		0xDF, 0x21, 0x01, 0x00,
+		0xDF, 0x22, 0x04}
+	data = append(data, sjis...)
+	data = append(data, 0xDF, 0x23, 0x01, 'X',
+		0xDF, 0x24, 0x08, '2', '0', '0', '0', '0', '1', '0', '1',
+		0xDF, 0x25, 0x01, '1')
+
+	_, err := parseTextAttrs(data, nil)
+	if err == nil {
+		t.Error("parseTextAttrs should fail for non UTF-8 names")
+	}
+	decode := func(b []byte) (string, error) {
+		if bytes.Equal(b, sjis) {
+			return "山田", nil
+		}
+		return "", errors.New("unexpected bytes")
+	}
+	attrs, err := parseTextAttrs(data, decode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attrs.Name != "山田" || attrs.Address != "X" {
+		t.Errorf("unexpected attributes: %+v", attrs)
+	}
+}
+
+func TestParseTextAttrsTrailer(t *testing.T) {
+	data := []byte{0xFF, 0x20, 0x22,
+		0xDF, 0x21, 0x01, 0x00,
+		0xDF, 0x22, 0x03, 'A', 'B', 'C',
+		0xDF, 0x23, 0x01, 'X',
+		0xDF, 0x24, 0x08, '2', '0', '0', '0', '0', '1', '0', '1',
+		0xDF, 0x25, 0x01, '1',
+		0xDF, 0x33, 0x02, 0x12, 0x34}
+	if _, err := parseTextAttrs(data, nil); err != nil {
+		t.Fatal(err)
+	}
+	trailer, err := TextAttrsTrailer(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(trailer, []byte{0xDF, 0x33, 0x02, 0x12, 0x34}) {
+		t.Errorf("unexpected trailer: % X", trailer)
+	}
+}
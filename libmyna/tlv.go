@@ -0,0 +1,100 @@
+// TLV Dump
+
+package libmyna
+
+import (
+	"github.com/jpki/myna/asn1"
+)
+
+// DERを解析したTLVの要素
+type TLV struct {
+	Class    int // asn1.ClassUniversalなど
+	Tag      int
+	Compound bool   // 構造型の場合はChildrenに子要素を格納します
+	Length   int    // 値のバイト数
+	Value    []byte // 値 (構造型の場合は子要素のDER)
+	Children []TLV
+}
+
+// 連結されたDERのTLVを順に解析します
+// 構造型の要素は子要素まで再帰的に解析します
+func ParseTLV(data []byte) ([]TLV, error) {
+	var tlvs []TLV
+	for len(data) > 0 {
+		var raw asn1.RawValue
+		rest, err := asn1.Unmarshal(data, &raw)
+		if err != nil {
+			return nil, err
+		}
+		tlv := TLV{
+			Class:    raw.Class,
+			Tag:      raw.Tag,
+			Compound: raw.IsCompound,
+			Length:   len(raw.Bytes),
+			Value:    raw.Bytes,
+		}
+		if raw.IsCompound {
+			tlv.Children, err = ParseTLV(raw.Bytes)
+			if err != nil {
+				return nil, err
+			}
+		}
+		tlvs = append(tlvs, tlv)
+		data = rest
+	}
+	return tlvs, nil
+}
+
+// BER-TLVを走査し、最初に見つかったtagの値を返します
+// tagはタグのバイト列をそのまま数値にしたもの(0x7F49など)で、構造型の要素は子要素まで探します
+// 券面事項入力補助APの証明書のようにDERではないTLVにも使えます
+func findTLV(data []byte, tag int) []byte {
+	for len(data) > 0 {
+		i := 0
+		t := int(data[i])
+		compound := data[i]&0x20 != 0
+		if data[i]&0x1F == 0x1F {
+			for {
+				i++
+				if i >= len(data) {
+					return nil
+				}
+				t = t<<8 | int(data[i])
+				if data[i]&0x80 == 0 {
+					break
+				}
+			}
+		}
+		i++
+		if i >= len(data) {
+			return nil
+		}
+		length := int(data[i])
+		if length&0x80 != 0 {
+			n := length & 0x7F
+			if n == 0 || n > 3 || i+n >= len(data) {
+				return nil
+			}
+			length = 0
+			for j := 1; j <= n; j++ {
+				length = length<<8 | int(data[i+j])
+			}
+			i += n
+		}
+		i++
+		if i+length > len(data) {
+			return nil
+		}
+		value := data[i : i+length]
+		if t == tag {
+			return value
+		}
+		if compound {
+			if found := findTLV(value, tag); found != nil {
+				return found
+			}
+		}
+		data = data[i+length:]
+	}
+	return nil
+}
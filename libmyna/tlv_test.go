@@ -0,0 +1,56 @@
+package libmyna
+
+import (
+	"testing"
+
+	"github.com/jpki/myna/asn1"
+)
+
+func TestParseTLV(t *testing.T) {
+	data := []byte{0xFF, 0x20, 0x09,
+		0xDF, 0x22, 0x03, 'A', 'B', 'C',
+		0xDF, 0x25, 0x00,
+		0x04, 0x01, 0x00}
+	tlvs, err := ParseTLV(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tlvs) != 2 {
+		t.Fatalf("len(tlvs) = %d, want 2", len(tlvs))
+	}
+	outer := tlvs[0]
+	if outer.Class != asn1.ClassPrivate || outer.Tag != 32 || !outer.Compound || outer.Length != 9 {
+		t.Errorf("outer = %+v", outer)
+	}
+	if len(outer.Children) != 2 || outer.Children[0].Tag != 34 ||
+		string(outer.Children[0].Value) != "ABC" || outer.Children[1].Length != 0 {
+		t.Errorf("children = %+v", outer.Children)
+	}
+	if tlvs[1].Class != asn1.ClassUniversal || tlvs[1].Tag != asn1.TagOctetString {
+		t.Errorf("trailing = %+v", tlvs[1])
+	}
+
+	if _, err = ParseTLV([]byte{0x30, 0x05, 0x01}); err == nil {
+		t.Error("ParseTLV should fail for truncated data")
+	}
+}
+
+func TestFindTLV(t *testing.T) {
+	data := []byte{0x5F, 0x20, 0x01, 0x00,
+		0x7F, 0x49, 0x81, 0x07,
+		0x81, 0x02, 0xAB, 0xCD,
+		0x82, 0x01, 0x03}
+	key := findTLV(data, 0x7F49)
+	if len(key) != 7 {
+		t.Fatalf("findTLV(7F49) = %X", key)
+	}
+	if got := findTLV(data, 0x81); string(got) != "\xAB\xCD" {
+		t.Errorf("findTLV(81) = %X", got)
+	}
+	if got := findTLV(key, 0x82); len(got) != 1 || got[0] != 0x03 {
+		t.Errorf("findTLV(82) = %X", got)
+	}
+	if got := findTLV(data[:10], 0x82); got != nil {
+		t.Errorf("findTLV should not read past truncated data: %X", got)
+	}
+}
@@ -0,0 +1,255 @@
+// APDU Transport and Trace
+
+package libmyna
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ebfe/scard"
+)
+
+// APDUの送受信を行うインターフェース
+// *scard.Cardはこのインターフェースを満たします
+type Transport interface {
+	Transmit(cmd []byte) ([]byte, error)
+}
+
+// PC/SCを使わずに指定したTransportで通信するReaderを作成します
+// Connect()を呼ぶ必要はありません
+func NewReaderWithTransport(transport Transport, opts ...func(*Reader)) *Reader {
+	reader := new(Reader)
+	reader.transport = transport
+	reader.profile = DefaultCardProfile
+	for _, opt := range opts {
+		opt(reader)
+	}
+	return reader
+}
+
+// 送受信したAPDUをwに記録します
+// 記録はFileTransportで再生できます。PINはXXに置き換えて記録します
+func Trace(w io.Writer) func(*Reader) {
+	return func(r *Reader) {
+		r.trace = w
+	}
+}
+
+// Traceと同様ですが、既に記録先が指定されている場合は両方に記録します
+func teeTrace(w io.Writer) func(*Reader) {
+	return func(r *Reader) {
+		if r.trace != nil {
+			r.trace = io.MultiWriter(r.trace, w)
+		} else {
+			r.trace = w
+		}
+	}
+}
+
+func (self *Reader) connected() bool {
+	return self.card != nil || self.transport != nil
+}
+
+// 送信するAPDUを変換する関数を指定します
+// ベンダー独自のラッパーやセキュアメッセージングの付加に使います。nilで解除します
+func (self *Reader) SetAPDUMiddleware(fn func(cmd []byte) []byte) {
+	self.apduMiddleware = fn
+}
+
+// 受信した応答を変換する関数を指定します
+// 変換後の応答は末尾にSW1,SW2を含む形式にしてください。nilで解除します
+func (self *Reader) SetResponseMiddleware(fn func(res []byte) []byte) {
+	self.responseMiddleware = fn
+}
+
+// Traceにはミドルウェアで変換する前のコマンドと変換した後の応答を記録します
+func (self *Reader) transmit(cmd []byte) ([]byte, error) {
+	if err := self.context().Err(); err != nil {
+		return nil, err
+	}
+	wire := cmd
+	if self.apduMiddleware != nil {
+		wire = self.apduMiddleware(cmd)
+	}
+	var res []byte
+	var err error
+	if self.transport != nil {
+		res, err = self.transmitWithTimeout(self.transport, wire)
+	} else if self.card != nil {
+		res, err = self.transmitWithTimeout(self.card, wire)
+	} else {
+		return nil, newError("NOT_CONNECTED", nil)
+	}
+	if err == nil && self.responseMiddleware != nil {
+		res = self.responseMiddleware(res)
+	}
+	if err == nil && self.trace != nil {
+		fmt.Fprintf(self.trace, "> %s\n< % X\n", traceCommand(cmd), res)
+	}
+	return res, err
+}
+
+// 1つのAPDUの応答を待つ時間を設定します。0の場合は応答があるまで待ちます
+// タイムアウトした場合はErrAPDUTimeoutを返し、カードとの接続を破棄します
+// (Sessionでは次の操作で再接続します)
+// Transportを指定した場合は、タイムアウトしたTransmitが戻るまで以降のAPDUもErrAPDUTimeoutになります
+func (self *Reader) SetAPDUTimeout(d time.Duration) {
+	self.apduTimeout = d
+}
+
+type transmitResult struct {
+	res []byte
+	err error
+}
+
+// 応答を待たずに戻った場合も送信中のTransmitは止められないため、
+// その完了を待ってから切断します
+// Transportを指定した場合は切断できないため、Transmitが戻るまで以降の送信をErrAPDUTimeoutにします
+func (self *Reader) transmitWithTimeout(transport Transport, wire []byte) ([]byte, error) {
+	if self.stalled != nil {
+		select {
+		case <-self.stalled:
+			self.stalled = nil
+		default:
+			return nil, ErrAPDUTimeout
+		}
+	}
+	if self.apduTimeout <= 0 {
+		return transport.Transmit(wire)
+	}
+	done := make(chan transmitResult, 1)
+	go func() {
+		res, err := transport.Transmit(wire)
+		done <- transmitResult{res, err}
+	}()
+	timer := time.NewTimer(self.apduTimeout)
+	defer timer.Stop()
+	select {
+	case result := <-done:
+		return result.res, result.err
+	case <-timer.C:
+	}
+	if card := self.card; card != nil && self.transport == nil {
+		self.card = nil
+		go func() {
+			<-done
+			card.Disconnect(scard.ResetCard)
+		}()
+	} else {
+		stalled := make(chan struct{})
+		self.stalled = stalled
+		go func() {
+			<-done
+			close(stalled)
+		}()
+	}
+	return nil, ErrAPDUTimeout
+}
+
+// VERIFY/CHANGE REFERENCE DATAのデータ部をXXに置き換えます
+func traceCommand(cmd []byte) string {
+	if len(cmd) > 5 && (cmd[1] == 0x20 || cmd[1] == 0x24) {
+		mask := strings.TrimSpace(strings.Repeat(" XX", len(cmd)-5))
+		return fmt.Sprintf("% X %s", cmd[:5], mask)
+	}
+	return fmt.Sprintf("% X", cmd)
+}
+
+type traceExchange struct {
+	cmd  []string // バイトごとのHEX (XXは任意のバイト)
+	resp []byte
+}
+
+// Traceで記録したAPDUを再生するTransport
+// 送信されたAPDUが記録と異なる場合はエラーを返します
+type FileTransport struct {
+	exchanges []traceExchange
+	pos       int
+	err       error
+}
+
+func NewFileTransport(path string) (*FileTransport, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return parseTrace(file)
+}
+
+// "> コマンド"と"< 応答"の行の組を読み込みます。#で始まる行は無視します
+func parseTrace(r io.Reader) (*FileTransport, error) {
+	var transport FileTransport
+	var cmd []string
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(text, ">") && cmd == nil:
+			cmd = strings.Fields(strings.ToUpper(text[1:]))
+		case strings.HasPrefix(text, "<") && cmd != nil:
+			resp := ToBytes(text[1:])
+			transport.exchanges = append(transport.exchanges,
+				traceExchange{cmd, resp})
+			cmd = nil
+		default:
+			return nil, newError("INVALID_TRACE_LINE", nil, line, text)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if cmd != nil {
+		return nil, newError("TRACE_NO_RESPONSE", nil)
+	}
+	return &transport, nil
+}
+
+func (self *traceExchange) match(cmd []byte) bool {
+	if len(self.cmd) != len(cmd) {
+		return false
+	}
+	for i, b := range self.cmd {
+		if b != "XX" && b != fmt.Sprintf("%02X", cmd[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (self *FileTransport) Transmit(cmd []byte) ([]byte, error) {
+	if self.err != nil {
+		return nil, self.err
+	}
+	if self.pos >= len(self.exchanges) {
+		self.err = newError("TRACE_EXHAUSTED", nil, cmd)
+		return nil, self.err
+	}
+	exchange := self.exchanges[self.pos]
+	if !exchange.match(cmd) {
+		self.err = newError("TRACE_MISMATCH", nil,
+			self.pos+1, strings.Join(exchange.cmd, " "), cmd)
+		return nil, self.err
+	}
+	self.pos++
+	return append([]byte(nil), exchange.resp...), nil
+}
+
+// 記録と異なるAPDUが送信された場合のエラーを返します
+func (self *FileTransport) Err() error {
+	return self.err
+}
+
+// 再生されていない記録の数を返します
+func (self *FileTransport) Remaining() int {
+	return len(self.exchanges) - self.pos
+}
@@ -0,0 +1,628 @@
+package libmyna
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// コマンドのHEX文字列から応答を返すTransport
+type mapTransport map[string][]byte
+
+func (self mapTransport) Transmit(cmd []byte) ([]byte, error) {
+	if res, ok := self[fmt.Sprintf("% X", cmd)]; ok {
+		return res, nil
+	}
+	return []byte{0x6A, 0x82}, nil
+}
+
+var testCard = mapTransport{
+	"00 A4 04 0C 0A D3 92 F0 00 26 01 00 00 00 01": {0x90, 0x00},
+	"00 A4 02 0C 02 00 06":                         {0x90, 0x00},
+	"00 B0 00 00 20":                               append([]byte("JPKIAPICCTOKEN2                 "), 0x90, 0x00),
+	"00 A4 02 0C 02 00 1B":                         {0x90, 0x00},
+	"00 20 00 80 06 41 42 43 31 32 33":             {0x90, 0x00},
+}
+
+func TestTraceReplay(t *testing.T) {
+	var trace bytes.Buffer
+	reader := NewReaderWithTransport(testCard, Trace(&trace))
+	jpkiAP, err := reader.SelectJPKIAP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := jpkiAP.GetToken()
+	if err != nil || token != "JPKIAPICCTOKEN2" {
+		t.Fatalf("GetToken() = %q, %v", token, err)
+	}
+	err = jpkiAP.VerifySignPin("ABC123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(trace.String(), "41 42 43") {
+		t.Error("PIN should be masked in the trace")
+	}
+
+	ft, err := parseTrace(strings.NewReader(trace.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader = NewReaderWithTransport(ft)
+	jpkiAP, err = reader.SelectJPKIAP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err = jpkiAP.GetToken()
+	if err != nil || token != "JPKIAPICCTOKEN2" {
+		t.Fatalf("replayed GetToken() = %q, %v", token, err)
+	}
+	err = jpkiAP.VerifySignPin("OTHER9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ft.Remaining() != 0 || ft.Err() != nil {
+		t.Errorf("Remaining() = %d, Err() = %v", ft.Remaining(), ft.Err())
+	}
+
+	ft, _ = parseTrace(strings.NewReader(trace.String()))
+	reader = NewReaderWithTransport(ft)
+	reader.SelectTextAP()
+	if ft.Err() == nil {
+		t.Error("replay should fail for a diverging APDU")
+	}
+}
+
+func TestReadBinaryAll(t *testing.T) {
+	ok := []byte{0x90, 0x00}
+	card := mapTransport{
+		"00 A4 02 0C 02 00 01": ok,
+		"00 B0 00 00 00":       append(bytes.Repeat([]byte{0x01}, 0x100), ok...),
+		"00 B0 01 00 00":       {0x6C, 0x2C},
+		"00 B0 01 00 2C":       append(bytes.Repeat([]byte{0x02}, 0x2C), ok...),
+	}
+	reader := NewReaderWithTransport(card)
+	data, err := reader.ReadBinaryAll("00 01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 0x12C || data[0xFF] != 0x01 || data[0x100] != 0x02 {
+		t.Errorf("unexpected data: len=%d", len(data))
+	}
+
+	_, err = reader.ReadBinaryAll("00 02")
+	if err == nil {
+		t.Error("ReadBinaryAll should fail for a missing EF")
+	}
+
+	// 6C00を返し続けるカード
+	card["00 A4 02 0C 02 00 03"] = ok
+	card["00 B0 00 00 00"] = []byte{0x6C, 0x00}
+	_, err = reader.ReadBinaryAll("00 03")
+	if !errors.Is(err, newError("READ_BINARY_RETRY", nil)) {
+		t.Errorf("err = %v, want READ_BINARY_RETRY", err)
+	}
+
+	// オフセットで指定できる範囲を超えるEF
+	reader = NewReaderWithTransport(&efCard{
+		efs: map[string][]byte{"/0004": make([]byte, 0x8100)},
+	})
+	_, err = reader.ReadBinaryAll("00 04")
+	if !errors.Is(err, newError("EF_TOO_LARGE", nil)) {
+		t.Errorf("err = %v, want EF_TOO_LARGE", err)
+	}
+}
+
+func TestGetUID(t *testing.T) {
+	reader := NewReaderWithTransport(mapTransport{
+		"FF CA 00 00 00": {0x04, 0x12, 0x34, 0x56, 0x90, 0x00},
+	})
+	uid, err := reader.GetUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(uid, []byte{0x04, 0x12, 0x34, 0x56}) {
+		t.Errorf("GetUID = % X", uid)
+	}
+
+	reader = NewReaderWithTransport(mapTransport{"FF CA 00 00 00": {0x6A, 0x81}})
+	_, err = reader.GetUID()
+	var apduErr *APDUError
+	if !errors.As(err, &apduErr) {
+		t.Errorf("GetUID should fail with APDUError: %v", err)
+	}
+}
+
+func TestSessionGetIdentity(t *testing.T) {
+	ok := []byte{0x90, 0x00}
+	mynumber := append([]byte{0xD0, 0x0C}, "123456789018"...)
+	attrs := []byte{0xFF, 0x20, 0x1D,
+		0xDF, 0x21, 0x01, 0x00,
+		0xDF, 0x22, 0x03, 'A', 'B', 'C',
+		0xDF, 0x23, 0x01, 'X',
+		0xDF, 0x24, 0x08, '2', '0', '0', '0', '0', '1', '0', '1',
+		0xDF, 0x25, 0x01, '2'}
+	card := mapTransport{
+		"00 A4 04 0C 0A D3 92 10 00 31 00 01 01 04 08": ok,
+		"00 A4 02 0C 02 00 11":                         ok,
+		"00 20 00 80 04 31 32 33 34":                   ok,
+		"00 A4 02 0C 02 00 01":                         ok,
+		"00 B0 00 00 11":                               append(append(mynumber, 0xFF, 0xFF, 0xFF), ok...),
+		"00 A4 02 0C 02 00 02":                         ok,
+		"00 B0 00 00 07":                               append(attrs[:7:7], ok...),
+		"00 B0 00 00 20":                               append(attrs[:len(attrs):len(attrs)], ok...),
+	}
+	session := NewSessionWithTransport(card)
+	identity, err := session.GetIdentity("1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.MyNumber != "123456789018" {
+		t.Errorf("MyNumber = %s", identity.MyNumber)
+	}
+	if identity.Attrs.Name != "ABC" || identity.Attrs.Sex != "2" {
+		t.Errorf("unexpected attributes: %+v", identity.Attrs)
+	}
+}
+
+func TestSessionCloseZeroesSecrets(t *testing.T) {
+	ok := []byte{0x90, 0x00}
+	mynumber := append([]byte{0xD0, 0x0C}, "123456789018"...)
+	card := mapTransport{
+		"00 A4 04 0C 0A D3 92 10 00 31 00 01 01 04 08": ok,
+		"00 A4 02 0C 02 00 11":                         ok,
+		"00 20 00 80 04 31 32 33 34":                   ok,
+		"00 A4 02 0C 02 00 01":                         ok,
+		"00 B0 00 00 11":                               append(append(mynumber, 0xFF, 0xFF, 0xFF), ok...),
+	}
+	pin := []byte("1234")
+	session := NewSessionWithTransport(card)
+	got, err := session.GetMyNumberBytes(func() ([]byte, error) { return pin, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "123456789018" {
+		t.Errorf("MyNumber = %s", got)
+	}
+	if !bytes.Equal(pin, make([]byte, 4)) {
+		t.Errorf("PIN is not zeroed: % X", pin)
+	}
+	session.Close()
+	if !bytes.Equal(got, make([]byte, 12)) {
+		t.Errorf("MyNumber is not zeroed after Close: % X", got)
+	}
+}
+
+func TestAuthenticateAP(t *testing.T) {
+	reader := NewReaderWithTransport(testCard)
+	if err := reader.AuthenticateAP("JPKI", "00 1B", "ABC123"); err != nil {
+		t.Error(err)
+	}
+	err := reader.AuthenticateAP("TEXT", "0011", "1234")
+	if !errors.Is(err, ErrAPNotFound) {
+		t.Errorf("AuthenticateAP should fail with ErrAPNotFound: %v", err)
+	}
+	err = reader.AuthenticateAP("JPKI", "00 18", "1234")
+	var stage *Error
+	if !errors.As(err, &stage) || stage.Code != "AUTH_SELECT_PIN_EF" {
+		t.Errorf("AuthenticateAP should fail at the PIN EF: %v", err)
+	}
+}
+
+func TestReadBinaryInto(t *testing.T) {
+	ok := []byte{0x90, 0x00}
+	card := mapTransport{
+		"00 A4 02 0C 02 00 01": ok,
+		"00 B0 00 00 00":       append(bytes.Repeat([]byte{0x01}, 0x100), ok...),
+		"00 B0 01 00 40":       append(bytes.Repeat([]byte{0x02}, 0x20), 0x62, 0x82),
+	}
+	reader := NewReaderWithTransport(card)
+	buf := make([]byte, 0x140)
+	n, err := reader.ReadBinaryInto("00 01", buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 0x120 || buf[0xFF] != 0x01 || buf[0x11F] != 0x02 {
+		t.Errorf("unexpected read: n=%d", n)
+	}
+}
+
+func TestReadBinaryWrongLength(t *testing.T) {
+	ok := []byte{0x90, 0x00}
+	card := mapTransport{
+		"00 B0 00 00 00": {0x67, 0x00},
+		"00 B0 00 00 80": append(bytes.Repeat([]byte{0x01}, 0x80), ok...),
+		"00 B0 00 80 80": append(bytes.Repeat([]byte{0x02}, 0x80), ok...),
+		"00 B0 01 00 40": append(bytes.Repeat([]byte{0x03}, 0x40), ok...),
+	}
+	reader := NewReaderWithTransport(card)
+	data, err := reader.readBinary(0x140, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 0x140 || data[0xFF] != 0x02 || data[0x13F] != 0x03 {
+		t.Errorf("unexpected read: % X", data)
+	}
+	// 以降の読み取りでは縮めた長さを使う
+	if reader.quirks.readChunkSize() != fallbackReadChunkSize {
+		t.Errorf("readChunkSize = %d, want %d", reader.quirks.readChunkSize(), fallbackReadChunkSize)
+	}
+
+	card = mapTransport{"00 B0 00 00 10": {0x67, 0x00}}
+	if _, err = NewReaderWithTransport(card).readBinary(0x10, nil); err == nil {
+		t.Error("readBinary should fail when a short read returns 6700")
+	}
+}
+
+func TestReadBinaryExtendedLength(t *testing.T) {
+	ok := []byte{0x90, 0x00}
+	card := mapTransport{
+		"00 B0 00 00 00 01 40": append(bytes.Repeat([]byte{0x01}, 0x140), ok...),
+	}
+	reader := NewReaderWithTransport(card)
+	reader.quirks.ExtendedLength = true
+	data, err := reader.readBinary(0x140, nil)
+	if err != nil || len(data) != 0x140 {
+		t.Fatalf("readBinary = %d bytes, %v", len(data), err)
+	}
+
+	// 拡張長が失敗した場合は短縮長で読み直す
+	card = mapTransport{
+		"00 B0 00 00 00": append(bytes.Repeat([]byte{0x01}, 0x100), ok...),
+		"00 B0 01 00 40": append(bytes.Repeat([]byte{0x02}, 0x40), ok...),
+	}
+	reader = NewReaderWithTransport(card)
+	reader.quirks.ExtendedLength = true
+	data, err = reader.readBinary(0x140, nil)
+	if err != nil || len(data) != 0x140 || data[0x13F] != 0x02 {
+		t.Fatalf("readBinary fallback = %d bytes, %v", len(data), err)
+	}
+	if reader.quirks.ExtendedLength {
+		t.Error("ExtendedLength should be disabled after the fallback")
+	}
+}
+
+func TestSelectAID(t *testing.T) {
+	reader := NewReaderWithTransport(testCard)
+	sw, err := reader.SelectAID(ToBytes(AIDJPKIAP))
+	if err != nil || !sw.IsSuccess() {
+		t.Errorf("SelectAID(JPKI) = %s, %v", sw, err)
+	}
+	sw, err = reader.SelectAID(ToBytes("A0 00 00 00 01"))
+	if err == nil || sw != 0x6A82 || sw.SW1() != 0x6A || sw.SW2() != 0x82 {
+		t.Errorf("SelectAID(unknown) = %s, %v", sw, err)
+	}
+	if _, err = reader.SelectAID([]byte{0xA0}); err == nil {
+		t.Error("SelectAID should reject a short AID")
+	}
+}
+
+func TestCancelContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	session := NewSessionWithTransport(testCard, CancelContext(ctx))
+	if _, err := session.Reader().SelectJPKIAP(); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if _, err := session.Reader().transmit(ToBytes("00 A4 02 0C 02 00 06")); err != context.Canceled {
+		t.Errorf("transmit after cancel = %v", err)
+	}
+	if err := contextError(ctx, errors.New("APDU Error")); err != context.Canceled {
+		t.Errorf("contextError = %v", err)
+	}
+}
+
+// APとEFの選択状態を持ち、選択したEFの内容をREAD BINARYで返すTransport
+// efsのキーは"AID/EF"のHEX文字列で、PINの照合は常に成功します
+// keyを指定した場合はCOMPUTE DIGITAL SIGNATUREにkeyで署名して応答します
+// uidを指定した場合はGET DATA(FF CA)にuidを返します
+type efCard struct {
+	efs map[string][]byte
+	key *rsa.PrivateKey
+	uid []byte
+	ap  string
+	ef  string
+}
+
+func (self *efCard) Transmit(cmd []byte) ([]byte, error) {
+	ok := []byte{0x90, 0x00}
+	switch {
+	case cmd[0] == 0xFF && cmd[1] == 0xCA && self.uid != nil:
+		return append(append([]byte{}, self.uid...), ok...), nil
+	case cmd[1] == 0xA4 && cmd[2] == 0x04:
+		self.ap = fmt.Sprintf("%X", cmd[5:5+cmd[4]])
+		return ok, nil
+	case cmd[1] == 0xA4 && cmd[2] == 0x02 && cmd[3] == 0x0C:
+		id := fmt.Sprintf("%X", cmd[5:5+cmd[4]])
+		if _, found := self.efs[self.ap+"/"+id]; !found {
+			return []byte{0x6A, 0x82}, nil
+		}
+		self.ef = id
+		return ok, nil
+	case cmd[1] == 0xB0:
+		data := self.efs[self.ap+"/"+self.ef]
+		pos := int(cmd[2])<<8 | int(cmd[3])
+		le := int(cmd[4])
+		if le == 0 {
+			le = 0x100
+		}
+		if pos+le > len(data) {
+			return []byte{0x6B, 0x00}, nil
+		}
+		return append(append([]byte{}, data[pos:pos+le]...), ok...), nil
+	case cmd[1] == 0x20:
+		return ok, nil
+	case cmd[1] == 0x2A && self.key != nil:
+		signature, err := rsa.SignPKCS1v15(rand.Reader, self.key, crypto.Hash(0), cmd[5:5+cmd[4]])
+		if err != nil {
+			return nil, err
+		}
+		return append(signature, ok...), nil
+	}
+	return []byte{0x6A, 0x86}, nil
+}
+
+func TestSessionProvision(t *testing.T) {
+	authCert, _ := newTestCert(t)
+	signCert, _ := newTestCert(t)
+	attrs := []byte{0xFF, 0x20, 0x1D,
+		0xDF, 0x21, 0x01, 0x00,
+		0xDF, 0x22, 0x03, 'A', 'B', 'C',
+		0xDF, 0x23, 0x01, 'X',
+		0xDF, 0x24, 0x08, '2', '0', '0', '0', '0', '1', '0', '1',
+		0xDF, 0x25, 0x01, '2'}
+	card := &efCard{efs: map[string][]byte{
+		AIDJPKIAP + "/000A": authCert.Raw,
+		AIDJPKIAP + "/001B": nil,
+		AIDJPKIAP + "/0001": signCert.Raw,
+		AIDTextAP + "/0011": nil,
+		AIDTextAP + "/0002": attrs,
+	}}
+	session := NewSessionWithTransport(card)
+	bundle, err := session.Provision("ABC123", "1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bundle.AuthCert.Equal(authCert) || !bundle.SignCert.Equal(signCert) {
+		t.Error("unexpected certificates")
+	}
+	if bundle.Attrs.Name != "ABC" {
+		t.Errorf("unexpected attributes: %+v", bundle.Attrs)
+	}
+}
+
+func TestAPDUMiddleware(t *testing.T) {
+	card := mapTransport{
+		"A5 00 A4 02 0C 02 00 06": {0x5A, 0x90, 0x00},
+	}
+	reader := NewReaderWithTransport(card)
+	reader.SetAPDUMiddleware(func(cmd []byte) []byte {
+		return append([]byte{0xA5}, cmd...)
+	})
+	reader.SetResponseMiddleware(func(res []byte) []byte {
+		return res[1:]
+	})
+	if err := reader.SelectEF("00 06"); err != nil {
+		t.Error(err)
+	}
+	reader.SetAPDUMiddleware(nil)
+	reader.SetResponseMiddleware(nil)
+	if err := reader.SelectEF("00 06"); err == nil {
+		t.Error("SelectEF should fail without the middleware")
+	}
+}
+
+func TestSessionHashAndSign(t *testing.T) {
+	cert, key := newTestCert(t)
+	card := &efCard{key: key, efs: map[string][]byte{
+		AIDJPKIAP + "/001B": nil,
+		AIDJPKIAP + "/0001": cert.Raw,
+		AIDJPKIAP + "/001A": nil,
+	}}
+	session := NewSessionWithTransport(card)
+	content := []byte("hello myna")
+	attrs, signature, signer, err := session.HashAndSign("ABC123", content, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !signer.Equal(cert) {
+		t.Error("unexpected signer certificate")
+	}
+	digest := sha256.Sum256(attrs)
+	if err = VerifyRawSignature(cert, crypto.SHA256, digest[:], signature); err != nil {
+		t.Error(err)
+	}
+	contentDigest := sha256.Sum256(content)
+	if !bytes.Contains(attrs, contentDigest[:]) {
+		t.Error("attributes should contain the message digest")
+	}
+	if _, _, _, err = session.HashAndSign("ABC123", content, crypto.MD5); err == nil {
+		t.Error("HashAndSign should reject unsupported digests")
+	}
+
+	signature, err = session.SignDigest("ABC123", crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = VerifyRawSignature(cert, crypto.SHA256, digest[:], signature); err != nil {
+		t.Error(err)
+	}
+	_, err = session.SignDigest("ABC123", crypto.SHA256, digest[:20])
+	if !errors.Is(err, newError("INVALID_DIGEST_SIZE", nil)) {
+		t.Errorf("SignDigest with a short digest = %v, want INVALID_DIGEST_SIZE", err)
+	}
+}
+
+func TestSessionVerifyCardAuthenticity(t *testing.T) {
+	authCert, _ := newTestCert(t)
+	other, _ := newTestCert(t)
+	card := &efCard{efs: map[string][]byte{
+		AIDJPKIAP + "/000A": authCert.Raw,
+		AIDJPKIAP + "/000B": authCert.Raw,
+	}}
+	session := NewSessionWithTransport(card)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(authCert)
+	ok, err := session.VerifyCardAuthenticity(roots)
+	if err != nil || !ok {
+		t.Errorf("VerifyCardAuthenticity = %v, %v", ok, err)
+	}
+
+	roots = x509.NewCertPool()
+	roots.AddCert(other)
+	ok, err = session.VerifyCardAuthenticity(roots)
+	if err != nil || ok {
+		t.Errorf("VerifyCardAuthenticity with other roots = %v, %v", ok, err)
+	}
+
+	if _, err = session.VerifyCardAuthenticity(nil); !errors.Is(err, newError("ROOTS_NOT_CONFIGURED", nil)) {
+		t.Errorf("VerifyCardAuthenticity without roots = %v", err)
+	}
+}
+
+func TestSessionGetJPKIAuthCertCached(t *testing.T) {
+	dir, err := ioutil.TempDir("", "myna")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cert, _ := newTestCert(t)
+	other, _ := newTestCert(t)
+	card := &efCard{
+		efs: map[string][]byte{AIDJPKIAP + "/000A": cert.Raw},
+		uid: []byte{0x01, 0x02, 0x03, 0x04},
+	}
+	session := NewSessionWithTransport(card)
+	got, err := session.GetJPKIAuthCertCached(dir)
+	if err != nil || !got.Equal(cert) {
+		t.Fatalf("GetJPKIAuthCertCached = %v", err)
+	}
+
+	// UIDが同じ間はカードの内容が変わってもキャッシュを返す
+	card.efs[AIDJPKIAP+"/000A"] = other.Raw
+	got, err = session.GetJPKIAuthCertCached(dir)
+	if err != nil || !got.Equal(cert) {
+		t.Errorf("GetJPKIAuthCertCached should return the cached certificate: %v", err)
+	}
+
+	card.uid = []byte{0x05, 0x06, 0x07, 0x08}
+	got, err = session.GetJPKIAuthCertCached(dir)
+	if err != nil || !got.Equal(other) {
+		t.Errorf("GetJPKIAuthCertCached should refresh on UID mismatch: %v", err)
+	}
+}
+
+func TestTeeTrace(t *testing.T) {
+	card := mapTransport{"00 20 00 80 04 31 32 33 34": {0x90, 0x00}}
+	var trace, transcript bytes.Buffer
+	reader := NewReaderWithTransport(card, Trace(&trace), teeTrace(&transcript))
+	sw1, sw2, _ := reader.Trans(NewAPDUCase3(0x00, 0x20, 0x00, 0x80, []byte("1234")))
+	if sw1 != 0x90 || sw2 != 0x00 {
+		t.Fatalf("VERIFY = %02X %02X", sw1, sw2)
+	}
+	if trace.String() != transcript.String() {
+		t.Errorf("transcript = %q, trace = %q", transcript.String(), trace.String())
+	}
+	if !strings.Contains(transcript.String(), "> 00 20 00 80 04 XX XX XX XX") ||
+		strings.Contains(transcript.String(), "31 32 33 34") {
+		t.Errorf("PIN should be redacted: %q", transcript.String())
+	}
+}
+
+func TestGetData(t *testing.T) {
+	ok := []byte{0x90, 0x00}
+	reader := NewReaderWithTransport(mapTransport{
+		"00 CA 5F 52 00": {0x6C, 0x03},
+		"00 CA 5F 52 03": append([]byte{0x80, 0x31, 0x80}, ok...),
+		"00 CA 00 66 00": {0x6A, 0x88},
+	})
+	data, err := reader.GetData(ToBytes(DataObjectHistoricalBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, []byte{0x80, 0x31, 0x80}) {
+		t.Errorf("GetData = % X", data)
+	}
+	_, err = reader.GetData(ToBytes(DataObjectCardData))
+	var apduErr *APDUError
+	if !errors.As(err, &apduErr) {
+		t.Errorf("GetData should fail with APDUError: %v", err)
+	}
+	if _, err = reader.GetData([]byte{1, 2, 3}); err == nil {
+		t.Error("GetData should reject a 3-byte tag")
+	}
+}
+
+func TestIdentifyCard(t *testing.T) {
+	cardType, err := NewReaderWithTransport(testCard).IdentifyCard()
+	if err != nil || cardType != CardTypeMyNumber {
+		t.Errorf("IdentifyCard = %v, %v", cardType, err)
+	}
+	cardType, err = NewReaderWithTransport(mapTransport{}).IdentifyCard()
+	if err != nil || cardType != CardTypeUnknown {
+		t.Errorf("IdentifyCard without JPKI-AP = %v, %v", cardType, err)
+	}
+
+	transport := &stalledTransport{release: make(chan struct{})}
+	defer close(transport.release)
+	reader := NewReaderWithTransport(transport)
+	reader.SetAPDUTimeout(10 * time.Millisecond)
+	_, err = reader.IdentifyCard()
+	if !errors.Is(err, ErrAPDUTimeout) {
+		t.Errorf("IdentifyCard = %v, want ErrAPDUTimeout", err)
+	}
+}
+
+// 応答を返さないTransport
+type stalledTransport struct {
+	release chan struct{}
+	calls   int32
+}
+
+func (self *stalledTransport) Transmit(cmd []byte) ([]byte, error) {
+	atomic.AddInt32(&self.calls, 1)
+	<-self.release
+	return []byte{0x90, 0x00}, nil
+}
+
+func TestAPDUTimeout(t *testing.T) {
+	transport := &stalledTransport{release: make(chan struct{})}
+	reader := NewReaderWithTransport(transport)
+	reader.SetAPDUTimeout(10 * time.Millisecond)
+	err := reader.SelectEF("00 01")
+	if !errors.Is(err, ErrAPDUTimeout) {
+		t.Errorf("SelectEF = %v, want ErrAPDUTimeout", err)
+	}
+	_, err = reader.readBinary(4, nil)
+	if !errors.Is(err, ErrAPDUTimeout) {
+		t.Errorf("readBinary = %v, want ErrAPDUTimeout", err)
+	}
+	if calls := atomic.LoadInt32(&transport.calls); calls != 1 {
+		t.Errorf("Transmit called %d times while stalled, want 1", calls)
+	}
+
+	close(transport.release)
+	deadline := time.Now().Add(time.Second)
+	for {
+		err = reader.SelectEF("00 01")
+		if !errors.Is(err, ErrAPDUTimeout) || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err != nil {
+		t.Errorf("SelectEF after the stalled call returned = %v", err)
+	}
+}
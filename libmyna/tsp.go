@@ -0,0 +1,136 @@
+// RFC 3161 Timestamp Token
+
+package libmyna
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/yu-ichiro/pkcs7"
+)
+
+var oidAttributeTimeStampToken = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 14}
+var oidContentTypeTSTInfo = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+
+type tstMessageImprint struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	HashedMessage []byte
+}
+
+// TSTInfoの先頭部分 (genTime以降は使わないので読み飛ばします)
+type tstInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint tstMessageImprint
+	SerialNumber   *big.Int
+	GenTime        time.Time `asn1:"generalized"`
+}
+
+type tstContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,tag:0"`
+}
+
+type tstSignedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	EncapContentInfo struct {
+		EContentType asn1.ObjectIdentifier
+	}
+}
+
+func tstHashForOID(oid asn1.ObjectIdentifier) (crypto.Hash, error) {
+	for _, alg := range digestAlgorithms {
+		if alg.oid.Equal(oid) {
+			return alg.hash, nil
+		}
+	}
+	return 0, newError("UNSUPPORTED_DIGEST", nil,
+		oid.String(), strings.Join(SupportedDigests(), ", "))
+}
+
+// タイムスタンプトークンを検証し、genTimeを返します
+// signatureはタイムスタンプの対象となった署名値です
+// rootsがnilの場合はTSA証明書を信頼できないためエラーを返します
+func verifyTimestampToken(token []byte, signature []byte, roots *x509.CertPool) (time.Time, error) {
+	if roots == nil {
+		return time.Time{}, newError("NO_TSA_ROOTS", nil)
+	}
+	var ci tstContentInfo
+	_, err := asn1.Unmarshal(token, &ci)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var sd tstSignedData
+	_, err = asn1.Unmarshal(ci.Content.Bytes, &sd)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !sd.EncapContentInfo.EContentType.Equal(oidContentTypeTSTInfo) {
+		return time.Time{}, newError("NOT_TIMESTAMP_TOKEN", nil)
+	}
+
+	p7, err := pkcs7.Parse(token)
+	if err != nil {
+		return time.Time{}, err
+	}
+	tsa := p7.GetOnlySigner()
+	if tsa == nil {
+		return time.Time{}, newError("NO_TSA_CERT", nil)
+	}
+	usable := false
+	for _, eku := range tsa.ExtKeyUsage {
+		if eku == x509.ExtKeyUsageTimeStamping {
+			usable = true
+		}
+	}
+	if !usable {
+		return time.Time{}, newError("TSA_CERT_USAGE", nil)
+	}
+	err = p7.VerifyWithChain(roots)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var info tstInfo
+	_, err = asn1.Unmarshal(p7.Content, &info)
+	if err != nil {
+		return time.Time{}, err
+	}
+	hash, err := tstHashForOID(info.MessageImprint.HashAlgorithm.Algorithm)
+	if err != nil {
+		return time.Time{}, err
+	}
+	h := hash.New()
+	h.Write(signature)
+	if !bytes.Equal(h.Sum(nil), info.MessageImprint.HashedMessage) {
+		return time.Time{}, newError("TIMESTAMP_MISMATCH", nil)
+	}
+	return info.GenTime, nil
+}
+
+// 署名者情報の非署名属性にあるタイムスタンプトークンを検証します
+// タイムスタンプトークンが無い場合はnilを返します
+func verifyCmsTimestamp(p7 *pkcs7.PKCS7, roots *x509.CertPool) (*time.Time, error) {
+	var genTime *time.Time
+	for _, signer := range p7.Signers {
+		for _, attr := range signer.UnauthenticatedAttributes {
+			if !attr.Type.Equal(oidAttributeTimeStampToken) {
+				continue
+			}
+			t, err := verifyTimestampToken(
+				attr.Value.Bytes, signer.EncryptedDigest, roots)
+			if err != nil {
+				return nil, err
+			}
+			genTime = &t
+		}
+	}
+	return genTime, nil
+}
@@ -0,0 +1,123 @@
+package libmyna
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/yu-ichiro/pkcs7"
+)
+
+func newTestTimestampToken(t *testing.T, signature []byte, genTime time.Time) ([]byte, *x509.Certificate) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "test tsa"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	imprint := sha256.Sum256(signature)
+	info, err := asn1.Marshal(tstInfo{
+		Version: 1,
+		Policy:  asn1.ObjectIdentifier{1, 2, 3},
+		MessageImprint: tstMessageImprint{
+			HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: pkcs7.OIDDigestAlgorithmSHA256},
+			HashedMessage: imprint[:],
+		},
+		SerialNumber: big.NewInt(1),
+		GenTime:      genTime,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sd, err := pkcs7.NewSignedData(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sd.GetSignedData().ContentInfo.ContentType = oidContentTypeTSTInfo
+	sd.SetDigestAlgorithm(pkcs7.OIDDigestAlgorithmSHA256)
+	err = sd.AddSigner(cert, key, pkcs7.SignerInfoConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := sd.Finish()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token, cert
+}
+
+func TestVerifyCmsTimestamp(t *testing.T) {
+	cert, key := newTestCert(t)
+	sd, err := pkcs7.NewSignedData([]byte("hello myna"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sd.AddSigner(cert, key, pkcs7.SignerInfoConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := &sd.GetSignedData().SignerInfos[0]
+	genTime := time.Now().UTC().Truncate(time.Second)
+	token, tsaCert := newTestTimestampToken(t, signer.EncryptedDigest, genTime)
+	err = signer.SetUnauthenticatedAttributes([]pkcs7.Attribute{
+		{Type: oidAttributeTimeStampToken, Value: asn1.RawValue{FullBytes: token}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := sd.Finish()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p7, err := pkcs7.Parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = verifyCmsTimestamp(p7, nil)
+	if !errors.Is(err, newError("NO_TSA_ROOTS", nil)) {
+		t.Errorf("err = %v, want NO_TSA_ROOTS", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(tsaCert)
+	got, err := verifyCmsTimestamp(p7, roots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || !got.Equal(genTime) {
+		t.Errorf("genTime = %v, want %v", got, genTime)
+	}
+
+	_, err = verifyTimestampToken(token, []byte("other signature"), roots)
+	if err == nil {
+		t.Error("verification should fail for a different signature")
+	}
+
+	_, err = verifyTimestampToken(token, signer.EncryptedDigest, x509.NewCertPool())
+	if err == nil {
+		t.Error("verification should fail for an untrusted TSA")
+	}
+}
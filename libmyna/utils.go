@@ -2,7 +2,10 @@ package libmyna
 
 import (
 	"encoding/hex"
-	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -32,12 +35,12 @@ func (self *ASN1PartialParser) GetSize() uint16 {
 func (self *ASN1PartialParser) parseTag(data []byte) error {
 	var tagsize uint16 = 1
 	if len(data) < 2 {
-		return errors.New("few data size")
+		return newError("ASN1_TOO_SHORT", nil)
 	}
 	if data[0]&0x1f == 0x1f {
 		tagsize++
 		if len(data) < 2 || data[1]&0x80 != 0 {
-			return errors.New("unexpected tag size")
+			return newError("ASN1_INVALID_TAG", nil)
 		}
 	}
 	self.offset = tagsize
@@ -46,7 +49,7 @@ func (self *ASN1PartialParser) parseTag(data []byte) error {
 
 func (self *ASN1PartialParser) parseLength(data []byte) error {
 	if int(self.offset) >= len(data) {
-		return errors.New("few data size")
+		return newError("ASN1_TOO_SHORT", nil)
 	}
 	b := data[self.offset]
 	self.offset++
@@ -56,7 +59,7 @@ func (self *ASN1PartialParser) parseLength(data []byte) error {
 		lol := int(b & 0x7f)
 		for i := 0; i < lol; i++ {
 			if int(self.offset) >= len(data) {
-				return errors.New("truncated tag or length")
+				return newError("ASN1_TOO_SHORT", nil)
 			}
 			b = data[self.offset]
 			self.offset++
@@ -78,3 +81,33 @@ func (self *ASN1PartialParser) Parse(data []byte) error {
 	}
 	return err
 }
+
+// 同じディレクトリの一時ファイルに書き込んでから置き換えます
+// 書き込みの途中で中断しても既存のファイルが壊れた状態になりません
+func writeFileAtomic(path string, perm os.FileMode, write func(io.Writer) error) error {
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	tmp, err := ioutil.TempFile(dir, "."+base+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	err = write(tmp)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	err = os.Chmod(tmp.Name(), perm)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,75 @@
+package libmyna
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "myna")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "out.p7s")
+
+	err = writeFileAtomic(path, 0644, func(w io.Writer) error {
+		_, err := w.Write([]byte("signed"))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = writeFileAtomic(path, 0644, func(w io.Writer) error {
+		w.Write([]byte("half"))
+		return errors.New("interrupted")
+	})
+	if err == nil {
+		t.Error("writeFileAtomic should return the write error")
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "signed" {
+		t.Errorf("file content = %q, want %q", data, "signed")
+	}
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 1 {
+		t.Errorf("temporary file is left: %d files", len(files))
+	}
+}
+
+func TestWriteCmsFileMode(t *testing.T) {
+	dir, err := ioutil.TempDir("", "myna")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		perm os.FileMode
+		want os.FileMode
+	}{
+		{0, 0644},
+		{0600, 0600},
+	}
+	for _, test := range tests {
+		path := filepath.Join(dir, "out.p7s")
+		if err = writeCms(path, []byte("signed"), "DER", test.perm); err != nil {
+			t.Fatal(err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != test.want {
+			t.Errorf("writeCms(%o) mode = %o, want %o", test.perm, info.Mode().Perm(), test.want)
+		}
+	}
+}
@@ -1,25 +1,133 @@
 package libmyna
 
 import (
-	"errors"
 	"regexp"
+	"strings"
 )
 
 func Validate4DigitPin(pin string) error {
 	match, _ := regexp.MatchString("^\\d{4}$", pin)
 	if !match {
-		return errors.New("暗証番号(4桁)を入力してください。")
+		return newError("INVALID_PIN_FORMAT", nil)
 	}
 	return nil
 }
 
 func ValidateJPKISignPassword(pass string) error {
-	if len(pass) < 4 || 16 < len(pass) {
-		return errors.New("パスワードの長さが正しくありません")
+	policy := pinPolicies["001B"]
+	if len(pass) < policy.MinLength || policy.MaxLength < len(pass) {
+		return newError("INVALID_PIN_LENGTH", nil)
 	}
 	match, _ := regexp.MatchString("^[A-Z0-9]+$", pass)
 	if !match {
-		return errors.New("パスワードの文字種が不正です")
+		return newError("INVALID_PIN_CHARS", nil)
+	}
+	return nil
+}
+
+// PINの種別に応じた形式で検証します
+//
+//	CARD_INPUT_HELPER 券面事項入力補助用PIN (数字4桁)
+//	JPKI_AUTH         利用者証明用PIN (数字4桁)
+//	JPKI_SIGN         署名用パスワード (英大文字と数字)
+//	VISUAL            券面APの照合番号A (個人番号12桁) または照合番号B (数字14桁)
+//
+// 署名用パスワードは大文字に変換してから渡してください
+func ValidatePin(pintype string, pin string) error {
+	switch pintype {
+	case "CARD_INPUT_HELPER", "JPKI_AUTH":
+		return Validate4DigitPin(pin)
+	case "JPKI_SIGN":
+		return ValidateJPKISignPassword(pin)
+	case "VISUAL":
+		match, _ := regexp.MatchString("^(\\d{12}|\\d{14})$", pin)
+		if !match {
+			return newError("INVALID_VISUAL_PIN", nil)
+		}
+		if len(pin) == 12 {
+			return ValidateMyNumber(pin)
+		}
+		return nil
+	default:
+		return newError("UNKNOWN_PIN_TYPE", nil, pintype)
+	}
+}
+
+type PinCharClass int
+
+const (
+	PinCharNumeric      PinCharClass = iota // 数字のみ
+	PinCharAlphanumeric                     // 英大文字と数字
+)
+
+func (self PinCharClass) String() string {
+	switch self {
+	case PinCharNumeric:
+		return "numeric"
+	case PinCharAlphanumeric:
+		return "alphanumeric"
+	default:
+		return "unknown"
+	}
+}
+
+type PinPolicy struct {
+	MinLength int
+	MaxLength int
+	CharClass PinCharClass
+}
+
+// カードからPINの仕様を取得する手段が無いため、既知のEFに対する仕様を返します
+//
+//	EF     AP               PIN
+//	00 11  券面入力補助AP   券面事項入力補助用PIN (数字4桁)
+//	00 14  券面入力補助AP   PIN A (個人番号 数字12桁)
+//	00 15  券面入力補助AP   PIN B (生年月日・有効期限・セキュリティコード 数字14桁)
+//	00 13  券面AP           PIN A (個人番号 数字12桁)
+//	00 12  券面AP           PIN B (生年月日・有効期限・セキュリティコード 数字14桁)
+//	00 18  JPKI-AP          利用者証明用PIN (数字4桁)
+//	00 1B  JPKI-AP          署名用パスワード (英大文字と数字6-16桁)
+var pinPolicies = map[string]PinPolicy{
+	"0011": {4, 4, PinCharNumeric},
+	"0014": {12, 12, PinCharNumeric},
+	"0015": {14, 14, PinCharNumeric},
+	"0013": {12, 12, PinCharNumeric},
+	"0012": {14, 14, PinCharNumeric},
+	"0018": {4, 4, PinCharNumeric},
+	"001B": {6, 16, PinCharAlphanumeric},
+}
+
+// PINを格納するEFの入力仕様を返します
+func GetPinPolicy(efid string) (*PinPolicy, error) {
+	key := strings.ToUpper(strings.Replace(efid, " ", "", -1))
+	policy, ok := pinPolicies[key]
+	if !ok {
+		return nil, newError("UNKNOWN_PIN_EF", nil, efid)
+	}
+	return &policy, nil
+}
+
+// 個人番号(12桁)の検査用数字を検証します
+func ValidateMyNumber(mynumber string) error {
+	match, _ := regexp.MatchString("^\\d{12}$", mynumber)
+	if !match {
+		return newError("INVALID_MYNUMBER", nil)
+	}
+	sum := 0
+	for n := 1; n <= 11; n++ {
+		p := int(mynumber[11-n] - '0')
+		q := n + 1
+		if n >= 7 {
+			q = n - 5
+		}
+		sum += p * q
+	}
+	check := 0
+	if r := sum % 11; r > 1 {
+		check = 11 - r
+	}
+	if int(mynumber[11]-'0') != check {
+		return newError("MYNUMBER_CHECK_DIGIT", nil)
 	}
 	return nil
 }
@@ -0,0 +1,51 @@
+package libmyna
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateMyNumber(t *testing.T) {
+	valid := []string{"123456789018", "000000000000"}
+	for _, s := range valid {
+		if err := ValidateMyNumber(s); err != nil {
+			t.Errorf("%s: %s", s, err)
+		}
+	}
+	invalid := []string{"", "12345678901", "123456789010", "12345678901A"}
+	for _, s := range invalid {
+		if err := ValidateMyNumber(s); err == nil {
+			t.Errorf("ValidateMyNumber should fail: %s", s)
+		}
+	}
+}
+
+func TestValidatePin(t *testing.T) {
+	tests := []struct {
+		pintype string
+		pin     string
+		valid   bool
+	}{
+		{"CARD_INPUT_HELPER", "1234", true},
+		{"CARD_INPUT_HELPER", "ABCD", false},
+		{"JPKI_AUTH", "12345", false},
+		{"JPKI_SIGN", "ABC123", true},
+		{"JPKI_SIGN", "abc123", false},
+		{"JPKI_SIGN", "ABCD", false},
+		{"JPKI_SIGN", "ABC12", false},
+		{"VISUAL", "123456789018", true},
+		{"VISUAL", "123456789010", false}, // 検査用数字が不正
+		{"VISUAL", "12345678901234", true},
+		{"VISUAL", "1234", false},
+		{"UNKNOWN", "1234", false},
+	}
+	for _, test := range tests {
+		err := ValidatePin(test.pintype, test.pin)
+		if (err == nil) != test.valid {
+			t.Errorf("ValidatePin(%s, %s) = %v", test.pintype, test.pin, err)
+		}
+	}
+	if err := ValidatePin("VISUAL", "1234"); !errors.Is(err, newError("INVALID_VISUAL_PIN", nil)) {
+		t.Errorf("err = %v, want INVALID_VISUAL_PIN", err)
+	}
+}
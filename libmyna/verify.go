@@ -0,0 +1,221 @@
+// Certificate Verification
+
+package libmyna
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+type CertVerifyOpts struct {
+	Roots       *x509.CertPool // nilの場合はカードのCA証明書を使います
+	OCSP        bool
+	CurrentTime time.Time // ゼロ値の場合は現在時刻
+}
+
+type CertVerifyResult struct {
+	OK         bool
+	Reasons    []string
+	Chain      []*x509.Certificate
+	OCSPStatus string
+}
+
+// Reasonsにはエラーコードに対応するメッセージを格納します
+func (self *CertVerifyResult) fail(code string, a ...interface{}) {
+	self.OK = false
+	self.Reasons = append(self.Reasons, newError(code, nil, a...).Error())
+}
+
+// VerifyCardAuthenticityで信頼点とする公的個人認証サービスのルート証明書
+// ライブラリには同梱していないため、J-LISが公開している証明書を読み込んで設定してください
+var JPKIRoots *x509.CertPool
+
+// PEMまたはDER形式の証明書ファイルを読み込んで信頼点とします
+// PEMの場合は1つのファイルに複数の証明書を含められます
+func LoadCertPool(files ...string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if block, _ := pem.Decode(data); block == nil {
+			cert, err := x509.ParseCertificate(data)
+			if err != nil {
+				return nil, err
+			}
+			pool.AddCert(cert)
+		} else if !pool.AppendCertsFromPEM(data) {
+			return nil, newError("INVALID_CERT_FILE", nil, file)
+		}
+	}
+	return pool, nil
+}
+
+// カードから利用者証明用・署名用のCA証明書を読み取り信頼点とします
+func GetJPKICACertPool() (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	authCA, err := GetJPKIAuthCACert()
+	if err != nil {
+		return nil, err
+	}
+	pool.AddCert(authCA)
+	signCA, err := GetJPKISignCACert()
+	if err != nil {
+		return nil, err
+	}
+	pool.AddCert(signCA)
+	return pool, nil
+}
+
+// 証明書の有効期間・証明書チェーン・(オプションで)OCSPを検証します
+//
+// 検証に失敗した場合もエラーは返さず、CertVerifyResult.Reasonsに理由を格納します。
+// エラーを返すのは信頼点の取得ができない場合のみです。
+func VerifyCertificateFull(cert *x509.Certificate, opts CertVerifyOpts) (*CertVerifyResult, error) {
+	var err error
+	roots := opts.Roots
+	if roots == nil {
+		roots, err = GetJPKICACertPool()
+		if err != nil {
+			return nil, err
+		}
+	}
+	now := opts.CurrentTime
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	result := CertVerifyResult{OK: true}
+	if now.Before(cert.NotBefore) {
+		result.fail("CERT_NOT_YET_VALID", cert.NotBefore)
+	}
+	if now.After(cert.NotAfter) {
+		result.fail("CERT_EXPIRED", cert.NotAfter)
+	}
+
+	chains, err := cert.Verify(x509.VerifyOptions{
+		Roots:       roots,
+		CurrentTime: now,
+		KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		result.fail("CERT_CHAIN_INVALID", err)
+		return &result, nil
+	}
+	result.Chain = chains[0]
+
+	if opts.OCSP {
+		if len(result.Chain) < 2 {
+			result.fail("OCSP_NO_ISSUER")
+			return &result, nil
+		}
+		status, err := checkOCSP(cert, result.Chain[1], now)
+		if err != nil {
+			result.fail("OCSP_CHECK_FAILED", err)
+			return &result, nil
+		}
+		result.OCSPStatus = status
+		if status != "good" {
+			result.fail("OCSP_CERT_STATUS", status)
+		}
+	}
+	return &result, nil
+}
+
+// OCSPの問い合わせに使うHTTPクライアント
+var ocspHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// nowの時点でNextUpdateを過ぎた応答は古い状態を示している可能性があるため拒否します
+func checkOCSP(cert *x509.Certificate, issuer *x509.Certificate, now time.Time) (string, error) {
+	if len(cert.OCSPServer) == 0 {
+		return "", newError("NO_OCSP_RESPONDER", nil)
+	}
+	req, err := ocsp.CreateRequest(cert, issuer, nil)
+	if err != nil {
+		return "", err
+	}
+	res, err := ocspHTTPClient.Post(cert.OCSPServer[0], "application/ocsp-request",
+		bytes.NewReader(req))
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", newError("OCSP_FAILED", nil, res.Status)
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+	resp, err := ocsp.ParseResponseForCert(body, cert, issuer)
+	if err != nil {
+		return "", err
+	}
+	if !resp.NextUpdate.IsZero() && now.After(resp.NextUpdate) {
+		return "", newError("OCSP_STALE", nil, resp.NextUpdate)
+	}
+	switch resp.Status {
+	case ocsp.Good:
+		return "good", nil
+	case ocsp.Revoked:
+		return "revoked", nil
+	default:
+		return "unknown", nil
+	}
+}
+
+var keyUsageNames = []struct {
+	usage x509.KeyUsage
+	name  string
+}{
+	{x509.KeyUsageDigitalSignature, "digitalSignature"},
+	{x509.KeyUsageContentCommitment, "nonRepudiation"},
+	{x509.KeyUsageKeyEncipherment, "keyEncipherment"},
+	{x509.KeyUsageDataEncipherment, "dataEncipherment"},
+	{x509.KeyUsageKeyAgreement, "keyAgreement"},
+	{x509.KeyUsageCertSign, "keyCertSign"},
+	{x509.KeyUsageCRLSign, "cRLSign"},
+	{x509.KeyUsageEncipherOnly, "encipherOnly"},
+	{x509.KeyUsageDecipherOnly, "decipherOnly"},
+}
+
+func KeyUsageString(usage x509.KeyUsage) string {
+	var names []string
+	for _, ku := range keyUsageNames {
+		if usage&ku.usage != 0 {
+			names = append(names, ku.name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// 証明書の鍵用途(KeyUsage)に必要なビットが全て含まれているか確認します
+func CheckCertKeyUsage(cert *x509.Certificate, required x509.KeyUsage) error {
+	missing := required &^ cert.KeyUsage
+	if missing != 0 {
+		return newError("KEY_USAGE_MISSING", nil, KeyUsageString(missing))
+	}
+	return nil
+}
+
+// ダイジェスト値に対するRSA PKCS#1 v1.5署名を証明書の公開鍵で検証します
+func VerifyRawSignature(cert *x509.Certificate, hash crypto.Hash, digest []byte, signature []byte) error {
+	pubkey, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return newError("NON_RSA_KEY", nil)
+	}
+	if len(digest) != hash.Size() {
+		return newError("INVALID_DIGEST_SIZE", nil, len(digest), hash.String(), hash.Size())
+	}
+	return rsa.VerifyPKCS1v15(pubkey, hash, digest, signature)
+}
@@ -0,0 +1,68 @@
+package libmyna
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+func TestVerifyRawSignature(t *testing.T) {
+	cert, key := newTestCert(t)
+	digest := sha256.Sum256([]byte("challenge"))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = VerifyRawSignature(cert, crypto.SHA256, digest[:], signature)
+	if err != nil {
+		t.Error(err)
+	}
+	other := sha256.Sum256([]byte("other"))
+	err = VerifyRawSignature(cert, crypto.SHA256, other[:], signature)
+	if err == nil {
+		t.Error("verification should fail for a different digest")
+	}
+	err = VerifyRawSignature(cert, crypto.SHA1, digest[:], signature)
+	if err == nil {
+		t.Error("verification should fail for a digest length mismatch")
+	}
+}
+
+func TestCheckOCSP(t *testing.T) {
+	cert, key := newTestCert(t)
+	now := time.Now()
+	var nextUpdate time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res, err := ocsp.CreateResponse(cert, cert, ocsp.Response{
+			Status:       ocsp.Good,
+			SerialNumber: cert.SerialNumber,
+			ThisUpdate:   now.Add(-2 * time.Hour),
+			NextUpdate:   nextUpdate,
+		}, key)
+		if err != nil {
+			t.Error(err)
+		}
+		w.Write(res)
+	}))
+	defer server.Close()
+	cert.OCSPServer = []string{server.URL}
+
+	nextUpdate = now.Add(time.Hour)
+	status, err := checkOCSP(cert, cert, now)
+	if err != nil || status != "good" {
+		t.Errorf("checkOCSP = %q, %v", status, err)
+	}
+	nextUpdate = now.Add(-time.Hour)
+	_, err = checkOCSP(cert, cert, now)
+	if !errors.Is(err, newError("OCSP_STALE", nil)) {
+		t.Errorf("checkOCSP with a stale response = %v, want OCSP_STALE", err)
+	}
+}
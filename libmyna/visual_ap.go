@@ -1,8 +1,6 @@
 package libmyna
 
 import (
-	"errors"
-
 	"github.com/jpki/myna/asn1"
 )
 
@@ -66,7 +64,7 @@ func (self *VisualAP) GetVisualInfo() (*VisualInfo, error) {
 	}
 	data := self.reader.ReadBinary(7)
 	if len(data) != 7 {
-		return nil, errors.New("Error at ReadBinary()")
+		return nil, newError("READ_BINARY_FAILED", nil)
 	}
 
 	parser := ASN1PartialParser{}